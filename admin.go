@@ -0,0 +1,107 @@
+package lemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// adminStatus is the JSON document served by the admin handler for hooks status.
+type adminStatus struct {
+	State State       `json:"state"`
+	Ready bool        `json:"ready"`
+	Hooks []adminHook `json:"hooks"`
+}
+
+// adminHook is the status of a hook in adminStatus.
+type adminHook struct {
+	Name     string     `json:"name"`
+	State    State      `json:"state"`
+	Since    time.Time  `json:"since"`
+	Started  *time.Time `json:"started,omitempty"`
+	Uptime   float64    `json:"uptime_seconds"`
+	Restarts int        `json:"restarts"`
+	Error    string     `json:"last_error,omitempty"`
+}
+
+// AdminHandler returns an http.Handler which exposes the engine's lifecycle with these endpoints:
+//
+//   - /healthz: liveness probe, which fails once the engine has stopped.
+//   - /readyz: readiness probe, which succeeds only while the engine is running: every hook is ready, healthy
+//     and no shutdown has been required.
+//   - /hooks: JSON listing of every hook with its state, uptime and last error.
+//
+// Use http.StripPrefix to serve these endpoints under a prefix.
+func (e *Engine) AdminHandler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, e.State() != StateStopped)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, e.available())
+	})
+
+	mux.HandleFunc("/hooks", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		_ = json.NewEncoder(w).Encode(e.adminStatus(time.Now()))
+	})
+
+	return mux
+}
+
+// adminStatus returns the status of the engine and its hooks at given time.
+func (e *Engine) adminStatus(now time.Time) adminStatus {
+
+	status := adminStatus{
+		State: e.State(),
+		Ready: e.available(),
+		Hooks: []adminHook{},
+	}
+
+	for _, h := range e.Hooks() {
+
+		hook := adminHook{
+			Name:     h.Name,
+			State:    h.State,
+			Since:    h.Since,
+			Restarts: h.Restarts,
+		}
+
+		if !h.Started.IsZero() {
+			started := h.Started
+			hook.Started = &started
+		}
+
+		if h.State == StateStarting || h.State == StateRunning || h.State == StateUnhealthy {
+			hook.Uptime = now.Sub(h.Started).Seconds()
+		}
+
+		if h.Err != nil {
+			hook.Error = h.Err.Error()
+		}
+
+		status.Hooks = append(status.Hooks, hook)
+	}
+
+	return status
+}
+
+// writeProbe writes the result of a probe.
+func writeProbe(w http.ResponseWriter, ok bool) {
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("unavailable\n"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
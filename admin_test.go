@@ -0,0 +1,137 @@
+package lemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdmin(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Probes": AdminProbes,
+		"Hooks":  AdminHooks,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+func (r *TestRuntime) HasStatusCode(handler http.Handler, path string, expected int) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if recorder.Code != expected {
+		r.Error("Unexpected status code for %s: %d", path, recorder.Code)
+	}
+}
+
+func AdminProbes(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), BeforeShutdown(func() {
+		// Give some time to check the readiness probe while the engine is shutting down.
+		time.Sleep(100 * time.Millisecond)
+	}))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	handler := engine.AdminHandler()
+
+	engine.Register(&readyHook{delay: 50 * time.Millisecond}, ReportsReady())
+
+	runtime.HasStatusCode(handler, "/healthz", http.StatusOK)
+	runtime.HasStatusCode(handler, "/readyz", http.StatusServiceUnavailable)
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasStatusCode(handler, "/healthz", http.StatusOK)
+	runtime.HasStatusCode(handler, "/readyz", http.StatusOK)
+
+	engine.Stop()
+	time.Sleep(50 * time.Millisecond)
+
+	runtime.HasStatusCode(handler, "/healthz", http.StatusOK)
+	runtime.HasStatusCode(handler, "/readyz", http.StatusServiceUnavailable)
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasStatusCode(handler, "/healthz", http.StatusServiceUnavailable)
+	runtime.HasStatusCode(handler, "/readyz", http.StatusServiceUnavailable)
+
+	runtime.Log("Admin handler has served liveness and readiness probes.")
+
+}
+
+func AdminHooks(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("database"))
+	engine.Register(&readyHook{delay: -1}, Name("server"), ReportsReady())
+
+	result := runtime.StartEngine(engine)
+	time.Sleep(50 * time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	engine.AdminHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/hooks", nil))
+
+	if recorder.Code != http.StatusOK {
+		runtime.Error("Unexpected status code: %d", recorder.Code)
+	}
+
+	status := adminStatus{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if status.State != StateStarting || status.Ready {
+		runtime.Error("Unexpected engine status: %+v", status)
+	}
+
+	if len(status.Hooks) != 2 {
+		runtime.Error("Unexpected hooks: %+v", status.Hooks)
+	}
+
+	database := status.Hooks[0]
+	if database.Name != "database" || database.State != StateRunning {
+		runtime.Error("Unexpected hook status: %+v", database)
+	}
+	if database.Started == nil || database.Uptime <= 0 {
+		runtime.Error("Hook %s should have an uptime: %+v", database.Name, database)
+	}
+
+	server := status.Hooks[1]
+	if server.Name != "server" || server.State != StateStarting {
+		runtime.Error("Unexpected hook status: %+v", server)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Admin handler has served hooks status: %s", recorder.Body.String())
+
+}
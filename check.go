@@ -0,0 +1,200 @@
+package lemon
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultCheckInterval is the default amount of time between two health checks of a hook.
+	DefaultCheckInterval = 10 * time.Second
+	// DefaultCheckTimeout is the default maximum amount of time of a health check.
+	DefaultCheckTimeout = time.Second
+	// DefaultCheckThreshold is the default number of consecutive failed health checks before an action is taken.
+	DefaultCheckThreshold = 3
+)
+
+// Checker is an optional interface for a Hook which can report its health.
+// Once a hook is ready, the engine will periodically execute its health check: see CheckPolicy.
+type Checker interface {
+	// Check returns an error if the hook is unhealthy.
+	// It should return once given context is done, which occurs after the check timeout.
+	Check(context.Context) error
+}
+
+// CheckAction defines what the engine does when a hook has failed its health checks.
+type CheckAction int
+
+const (
+	// CheckMarkNotReady marks the hook as unhealthy, so the engine isn't ready, until a health check succeeds.
+	CheckMarkNotReady CheckAction = iota
+	// CheckRestart restarts the hook.
+	CheckRestart
+	// CheckShutdown shutdowns the engine, with a HookError as cause.
+	CheckShutdown
+)
+
+// CheckPolicy defines how the health of a hook is checked.
+//
+// A health check is executed every Interval, and it fails if it doesn't return before Timeout. Once Threshold
+// consecutive health checks have failed, the engine executes the given Action.
+type CheckPolicy struct {
+	Interval  time.Duration
+	Timeout   time.Duration
+	Threshold int
+	Action    CheckAction
+}
+
+// HealthCheck defines the policy used to check the health of the registered hook, which must implement Checker.
+// Zero values of given policy are replaced by their default value.
+func HealthCheck(policy CheckPolicy) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+		h.check = policy
+	})
+}
+
+// normalize replaces zero, or invalid, values of the policy by their default value.
+func (p CheckPolicy) normalize() CheckPolicy {
+
+	if p.Interval <= 0 {
+		p.Interval = DefaultCheckInterval
+	}
+
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultCheckTimeout
+	}
+
+	if p.Threshold <= 0 {
+		p.Threshold = DefaultCheckThreshold
+	}
+
+	return p
+}
+
+// invoke executes given callback of a hook, such as a health check, which fails if it panics or if it doesn't
+// return before given context is done. The error returned is a HookError for given hook and phase, or PhasePanic
+// if the callback has panicked.
+func invoke(ctx context.Context, name string, phase Phase, callback func(context.Context) error) error {
+
+	result := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			value := recover()
+			if value != nil {
+				result <- recovered(name, phase, value)
+			}
+		}()
+		result <- wrapError(name, phase, callback(ctx))
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return wrapError(name, phase, ctx.Err())
+	}
+}
+
+// monitor will periodically check the health of given hook, if it implements Checker, until given context is done.
+// Health checks are only executed while the hook is ready.
+func (e *Engine) monitor(ctx context.Context, h *hookEntry, runtime *HookRuntime) {
+
+	checker, ok := h.hook.(Checker)
+	if !ok {
+		return
+	}
+
+	policy := h.check.normalize()
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		e.mutex.Lock()
+		state := h.state
+		e.mutex.Unlock()
+
+		if state != StateRunning && state != StateUnhealthy {
+			failures = 0
+			continue
+		}
+
+		cctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+		err := invoke(cctx, h.name, PhaseCheck, checker.Check)
+		cancel()
+
+		if err == nil {
+			failures = 0
+			e.running(h)
+			continue
+		}
+
+		failures++
+		if failures < policy.Threshold {
+			continue
+		}
+
+		failures = 0
+		if !e.react(h, runtime, policy.Action, err) {
+			return
+		}
+	}
+}
+
+// react executes given action once the hook has failed its health checks.
+// It returns false if the engine is shutting down, so health checks are over.
+func (e *Engine) react(h *hookEntry, runtime *HookRuntime, action CheckAction, err error) bool {
+
+	e.failure(h, err)
+	e.publish(Event{Type: EventHookFailed, Hook: h.name, Err: err})
+
+	switch action {
+	case CheckRestart:
+		runtime.Interrupt()
+	case CheckShutdown:
+		e.abort(err)
+		return false
+	default:
+		e.unhealthy(h)
+	}
+
+	return true
+}
+
+// unhealthy changes the state of given hook to unhealthy, if it's running.
+func (e *Engine) unhealthy(h *hookEntry) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if h.state == StateRunning {
+		h.transition(StateUnhealthy)
+	}
+}
+
+// available returns if the engine is running and every hook is healthy.
+func (e *Engine) available() bool {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.state != StateRunning {
+		return false
+	}
+
+	for _, h := range e.hooks {
+		if h.state == StateUnhealthy {
+			return false
+		}
+	}
+
+	return true
+}
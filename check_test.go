@@ -0,0 +1,226 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	tests := map[string]TestHandler{
+		"MarkNotReady": CheckWithMarkNotReady,
+		"Restart":      CheckWithRestart,
+		"Shutdown":     CheckWithShutdown,
+		"Timeout":      CheckWithTimeout,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+var errUnhealthy = errors.New("service is unhealthy: foobar")
+
+type checkHook struct {
+	starts    int64
+	unhealthy int32
+	blocking  bool
+}
+
+func (c *checkHook) Start(ctx context.Context) error {
+	atomic.AddInt64(&c.starts, 1)
+	<-ctx.Done()
+	return nil
+}
+
+func (c *checkHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (c *checkHook) Check(ctx context.Context) error {
+	if c.blocking {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if atomic.LoadInt32(&c.unhealthy) == 1 {
+		return errUnhealthy
+	}
+	return nil
+}
+
+func (c *checkHook) SetHealthy(healthy bool) {
+	if healthy {
+		atomic.StoreInt32(&c.unhealthy, 0)
+	} else {
+		atomic.StoreInt32(&c.unhealthy, 1)
+	}
+}
+
+func (c *checkHook) Starts() int64 {
+	return atomic.LoadInt64(&c.starts)
+}
+
+func CheckWithMarkNotReady(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &checkHook{}
+	engine.Register(hook, Name("hook"), HealthCheck(CheckPolicy{
+		Interval:  10 * time.Millisecond,
+		Threshold: 2,
+		Action:    CheckMarkNotReady,
+	}))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !engine.available() {
+		runtime.Error("Engine should be available")
+	}
+
+	hook.SetHealthy(false)
+	time.Sleep(50 * time.Millisecond)
+
+	runtime.HasHookStates(engine, StateUnhealthy)
+	if engine.available() {
+		runtime.Error("Engine shouldn't be available")
+	}
+
+	hook.SetHealthy(true)
+	time.Sleep(30 * time.Millisecond)
+
+	runtime.HasHookStates(engine, StateRunning)
+	if !engine.available() {
+		runtime.Error("Engine should be available")
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) == 0 {
+		runtime.Error("Failures were expected")
+	}
+	runtime.IsHookError(failures[0], "hook", PhaseCheck, errUnhealthy)
+
+	runtime.Log("Engine has marked an unhealthy hook as not ready.")
+
+}
+
+func CheckWithRestart(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &checkHook{}
+	engine.Register(hook, HealthCheck(CheckPolicy{
+		Interval:  10 * time.Millisecond,
+		Threshold: 2,
+		Action:    CheckRestart,
+	}))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook.SetHealthy(false)
+	time.Sleep(35 * time.Millisecond)
+	hook.SetHealthy(true)
+	time.Sleep(30 * time.Millisecond)
+
+	if hook.Starts() < 2 {
+		runtime.Error("Hook should have been restarted: %d", hook.Starts())
+	}
+
+	status := engine.Hooks()[0]
+	if status.Restarts < 1 || status.State != StateRunning {
+		runtime.Error("Unexpected hook status: %+v", status)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has restarted an unhealthy hook.")
+
+}
+
+func CheckWithShutdown(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &checkHook{unhealthy: 1}
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+	engine.Register(hook, Name("hook"), HealthCheck(CheckPolicy{
+		Interval:  10 * time.Millisecond,
+		Threshold: 3,
+		Action:    CheckShutdown,
+	}))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "hook", PhaseCheck, errUnhealthy)
+
+	runtime.Log("Engine has shutdown because of an unhealthy hook.")
+
+}
+
+func CheckWithTimeout(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &checkHook{blocking: true}
+	engine.Register(hook, Name("hook"), HealthCheck(CheckPolicy{
+		Interval:  10 * time.Millisecond,
+		Timeout:   10 * time.Millisecond,
+		Threshold: 1,
+		Action:    CheckShutdown,
+	}))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "hook", PhaseCheck, context.DeadlineExceeded)
+
+	runtime.Log("Engine has shutdown because of a health check timeout.")
+
+}
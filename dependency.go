@@ -0,0 +1,162 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrMissingDependency is returned when a hook depends on another hook which is not registered on the engine.
+	ErrMissingDependency = errors.New("invalid dependency: a hook depends on an unregistered hook")
+	// ErrCircularDependency is returned when hooks depend on each other.
+	ErrCircularDependency = errors.New("invalid dependency: hooks have a circular dependency")
+)
+
+// Dependent is an optional interface for a Hook which requires other hooks to be ready before it starts.
+//
+// The engine will start a dependent hook once its dependencies are ready, and it will stop this hook before its
+// dependencies, in the reverse order.
+type Dependent interface {
+	// Dependencies returns hooks that must be started before, and stopped after, this hook.
+	Dependencies() []Hook
+}
+
+// DependsOn declares that the registered hook requires given hooks to be ready before it starts.
+// It's an alternative to the Dependent interface, and both can be used at the same time.
+func DependsOn(hooks ...Hook) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+		h.dependencies = append(h.dependencies, hooks...)
+	})
+}
+
+// find returns the entry of given hook, if it's registered on engine.
+func (e *Engine) find(hook Hook) *hookEntry {
+	for _, h := range e.hooks {
+		if h.hook == hook {
+			return h
+		}
+	}
+	return nil
+}
+
+// resolve links every registered hook with its dependencies, and sorts them in a topological order: every hook is
+// placed after its dependencies.
+// An error is returned if a hook has an invalid option, if a dependency is not registered, or if there is a circular
+// dependency.
+func (e *Engine) resolve() ([]*hookEntry, error) {
+
+	for _, h := range e.hooks {
+		e.prepare(h)
+	}
+
+	for _, h := range e.hooks {
+		err := e.link(h)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	hooks := make([]*hookEntry, 0, len(e.hooks))
+	marks := make(map[*hookEntry]int, len(e.hooks))
+
+	var visit func(h *hookEntry) error
+	visit = func(h *hookEntry) error {
+		switch marks[h] {
+		case visiting:
+			return wrapError(h.name, PhaseStart, ErrCircularDependency)
+		case visited:
+			return nil
+		}
+
+		marks[h] = visiting
+		for _, dependency := range h.requires {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		marks[h] = visited
+
+		hooks = append(hooks, h)
+		return nil
+	}
+
+	for _, h := range e.hooks {
+		if err := visit(h); err != nil {
+			return nil, err
+		}
+	}
+
+	return hooks, nil
+}
+
+// prepare resets given hook before it's launched.
+// It must be called with the engine's mutex.
+func (e *Engine) prepare(h *hookEntry) {
+	h.requires = nil
+	h.dependents = nil
+	h.cancel = nil
+	h.reset(func() {
+		e.running(h)
+	})
+}
+
+// link resolves the dependencies of given hook.
+// An error is returned if the hook has an invalid option, if a dependency is not registered, or if its stage is not
+// declared.
+// It must be called with the engine's mutex.
+func (e *Engine) link(h *hookEntry) error {
+
+	if h.err != nil {
+		return wrapError(h.name, PhaseStart, h.err)
+	}
+
+	for _, dependency := range h.dependencies {
+		entry := e.find(dependency)
+		if entry == nil {
+			return wrapError(h.name, PhaseStart, ErrMissingDependency)
+		}
+		if entry == h {
+			return wrapError(h.name, PhaseStart, ErrCircularDependency)
+		}
+		require(h, entry)
+	}
+
+	return e.linkStage(h)
+}
+
+// waitDependencies will block until every dependency of given hook is ready.
+// It returns false if the given context is done before.
+func (h *hookEntry) waitDependencies(ctx context.Context) bool {
+	for _, dependency := range h.requires {
+		select {
+		case <-dependency.ready:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// waitDependents will block until every hook that depends on given hook has shutdown, or until the shutdown is
+// forced.
+func (e *Engine) waitDependents(h *hookEntry) {
+
+	// Dependents may be registered while the engine is running.
+	e.mutex.Lock()
+	dependents := append([]*hookEntry{}, h.dependents...)
+	e.mutex.Unlock()
+
+	for _, dependent := range dependents {
+		select {
+		case <-dependent.done:
+		case <-e.forced:
+			return
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDependency(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Order":     DependencyOrder,
+		"Interface": DependencyInterface,
+		"Missing":   DependencyMissing,
+		"Circular":  DependencyCircular,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// testJournal records the lifecycle events of orderHook.
+type testJournal struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (j *testJournal) Add(event string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.events = append(j.events, event)
+}
+
+func (j *testJournal) Index(event string) int {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for i := range j.events {
+		if j.events[i] == event {
+			return i
+		}
+	}
+	return -1
+}
+
+type orderHook struct {
+	id       string
+	journal  *testJournal
+	requires []Hook
+}
+
+func (o *orderHook) Start(ctx context.Context) error {
+	o.journal.Add("start:" + o.id)
+	Ready(ctx)
+	<-ctx.Done()
+	return nil
+}
+
+func (o *orderHook) Stop(ctx context.Context) error {
+	// Give some time to a dependency which would be wrongly stopped concurrently.
+	time.Sleep(20 * time.Millisecond)
+	o.journal.Add("stop:" + o.id)
+	return nil
+}
+
+type dependentHook struct {
+	orderHook
+}
+
+func (o *dependentHook) Dependencies() []Hook {
+	return o.requires
+}
+
+func (r *TestRuntime) HasOrder(journal *testJournal, before, after string) {
+	i := journal.Index(before)
+	j := journal.Index(after)
+	if i == -1 || j == -1 || i > j {
+		r.Error("Event %s should have been recorded before %s: %v", before, after, journal.events)
+	}
+}
+
+func DependencyOrder(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	journal := &testJournal{}
+	database := &orderHook{id: "database", journal: journal}
+	cache := &orderHook{id: "cache", journal: journal}
+	server := &orderHook{id: "server", journal: journal}
+
+	// Register hooks in the wrong order on purpose.
+	engine.Register(server, DependsOn(cache, database))
+	engine.Register(cache, DependsOn(database))
+	engine.Register(database)
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasOrder(journal, "start:database", "start:cache")
+	runtime.HasOrder(journal, "start:cache", "start:server")
+	runtime.HasOrder(journal, "stop:server", "stop:cache")
+	runtime.HasOrder(journal, "stop:cache", "stop:database")
+
+	runtime.Log("Engine has started and stopped hooks following their dependencies.")
+
+}
+
+func DependencyInterface(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	journal := &testJournal{}
+	database := &orderHook{id: "database", journal: journal}
+	worker := &dependentHook{orderHook{id: "worker", journal: journal, requires: []Hook{database}}}
+
+	engine.Register(worker)
+	engine.Register(database)
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasOrder(journal, "start:database", "start:worker")
+	runtime.HasOrder(journal, "stop:worker", "stop:database")
+
+	runtime.Log("Engine has used Dependent interface to order hooks.")
+
+}
+
+func DependencyMissing(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, DependsOn(hook2))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrMissingDependency) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	hook1.mutex.Lock()
+	defer hook1.mutex.Unlock()
+	if hook1.startCalled {
+		runtime.Error("Hook shouldn't have been started.")
+	}
+
+	runtime.Log("Engine has refused to start with a missing dependency.")
+
+}
+
+func DependencyCircular(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+	hook3 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, DependsOn(hook3))
+	engine.Register(hook2, DependsOn(hook1))
+	engine.Register(hook3, DependsOn(hook2))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrCircularDependency) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine has refused to start with a circular dependency.")
+
+}
@@ -0,0 +1,109 @@
+package lemon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PreStopper is an optional interface for a Hook which has to prepare its shutdown before any hook is stopped,
+// such as deregistering itself from a service discovery, or failing its readiness for a load balancer.
+type PreStopper interface {
+	// PreStop is executed once a shutdown is required, before the drain delay. The given context is done once the
+	// hook's shutdown timeout has expired.
+	PreStop(ctx context.Context) error
+}
+
+// DrainDelay sets the amount of time the engine will wait, once a shutdown is required, before stopping hooks.
+//
+// During this pre-stop phase, the engine is reported as not ready, so load balancers could stop sending traffic,
+// and every running hook that implements PreStopper is notified. Hooks are stopped once the drain delay has
+// expired and every PreStop() has returned. The drain is skipped if the shutdown is forced, or if a hook has failed.
+func DrainDelay(delay time.Duration) Option {
+	return wrapOption(func(e *Engine) error {
+
+		if delay <= 0 {
+			return ErrTimeout
+		}
+
+		e.drainDelay = delay
+		return nil
+
+	})
+}
+
+// drain executes the pre-stop phase: it notifies every running hook that implements PreStopper, and it waits for
+// the drain delay.
+func (e *Engine) drain() {
+
+	e.mutex.Lock()
+	hooks := e.preStoppers()
+	e.mutex.Unlock()
+
+	if len(hooks) == 0 && e.drainDelay <= 0 {
+		return
+	}
+
+	done := e.preStop(hooks)
+
+	timer := time.NewTimer(e.drainDelay)
+	defer timer.Stop()
+
+	// Both the drain delay and every PreStop() must be done before hooks are stopped.
+	delay := timer.C
+	for done != nil || delay != nil {
+		select {
+		case <-done:
+			done = nil
+		case <-delay:
+			delay = nil
+		case <-e.forced:
+			return
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+// preStoppers returns every running hook that implements PreStopper.
+// It must be called with the engine's mutex.
+func (e *Engine) preStoppers() []*hookEntry {
+	hooks := []*hookEntry{}
+	for _, h := range e.hooks {
+		_, ok := h.hook.(PreStopper)
+		if ok && h.isRunning() {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// preStop executes PreStop() of given hooks concurrently, and returns a channel closed once they have returned.
+func (e *Engine) preStop(hooks []*hookEntry) <-chan struct{} {
+
+	wait := &sync.WaitGroup{}
+	wait.Add(len(hooks))
+
+	for _, h := range hooks {
+		go func(h *hookEntry) {
+			defer wait.Done()
+
+			ctx, cancel := context.WithTimeout(e.ctx, e.shutdownTimeout(h))
+			defer cancel()
+
+			err := invoke(ctx, h.name, PhasePreStop, h.hook.(PreStopper).PreStop)
+			if err != nil {
+				e.failure(h, err)
+				e.publish(Event{Type: EventHookFailed, Hook: h.name, Err: err})
+			}
+		}(h)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wait.Wait()
+		close(done)
+	}()
+
+	return done
+}
@@ -0,0 +1,197 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Delay":     DrainWithDelay,
+		"Forced":    DrainForced,
+		"Error":     DrainWithPreStopError,
+		"ErrOption": DrainErrOption,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// drainHook records the state of the engine when its PreStop() is executed.
+type drainHook struct {
+	testHook
+	engine    *Engine
+	err       error
+	mutex     sync.Mutex
+	preStop   bool
+	available bool
+	stopped   bool
+}
+
+func (d *drainHook) PreStop(ctx context.Context) error {
+
+	d.testHook.mutex.Lock()
+	stopped := d.testHook.stopCalled
+	d.testHook.mutex.Unlock()
+
+	available := d.engine.available()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.preStop = true
+	d.available = available
+	d.stopped = stopped
+
+	return d.err
+}
+
+func DrainWithDelay(runtime *TestRuntime) {
+
+	delay := 200 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+
+	engine, err := New(runtime.Context(), DisableSignal(), DrainDelay(delay))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &drainHook{testHook: testHook{kill: make(chan struct{}, 1)}, engine: engine}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	now := time.Now()
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InEpsilon(delta, delay, epsilon, "Engine has shutdown with an unexpected amount of time...")
+	runtime.HasLifecycle(&hook1.testHook, "hook1")
+	runtime.HasLifecycle(hook2, "hook2")
+
+	hook1.mutex.Lock()
+	defer hook1.mutex.Unlock()
+
+	if !hook1.preStop {
+		runtime.Error("PreStop should have been executed")
+	}
+	if hook1.available {
+		runtime.Error("Engine should be reported as not ready during pre-stop")
+	}
+	if hook1.stopped {
+		runtime.Error("PreStop should have been executed before Stop")
+	}
+
+	runtime.Log("Engine has drained for %s before stopping hooks.", delta)
+
+}
+
+func DrainForced(runtime *TestRuntime) {
+
+	epsilon := 60 * time.Millisecond
+
+	engine, err := New(runtime.Context(), DisableSignal(), DrainDelay(time.Minute), Escalate(EscalationPolicy{}))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	runtime.Interrupt(engine, 2, 20*time.Millisecond)
+
+	now := time.Now()
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InDelta(delta, epsilon, "Engine took way too long to shutdown")
+
+	runtime.Log("Engine has skipped its drain delay on a repeated signal.")
+
+}
+
+func DrainWithPreStopError(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &drainHook{
+		testHook: testHook{kill: make(chan struct{}, 1)},
+		engine:   engine,
+		err:      errors.New("an error has occurred: foobar"),
+	}
+
+	engine.Register(hook, Name("hook"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(&hook.testHook, "hook")
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("A failure was expected: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "hook", PhasePreStop, hook.err)
+
+	runtime.Log("Engine has stopped hooks after a pre-stop failure: %s", failures[0])
+
+}
+
+func DrainErrOption(runtime *TestRuntime) {
+
+	_, err := New(runtime.Context(), DrainDelay(0))
+	if err != ErrTimeout {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine's configuration can't have an empty drain delay.")
+
+}
@@ -2,6 +2,7 @@ package lemon
 
 import (
 	"context"
+	"log/slog"
 	"os"
 	"sync"
 	"time"
@@ -13,6 +14,9 @@ import (
 //
 // It will start every registered hook (or daemon, service, etc...) and block until it
 // receives a SIGINT, SIGTERM or SIGQUIT signal.
+// A hook with dependencies (see Dependent and DependsOn) is started once its dependencies are ready.
+// Hooks could also be grouped in ordered stages (see Stage and InStage), each one started once the previous
+// stages are ready.
 //
 // For example:
 //
@@ -26,14 +30,30 @@ import (
 //
 // Stop
 //
-// When your application has to stop, the engine will notify every hook to shutdown gracefully.
-// However, if a hook fails to stop before timeout, the underlying goroutine will be destroyed...
+// When your application has to stop, the engine will notify every hook to shutdown gracefully, after an optional
+// pre-stop phase (see DrainDelay and PreStopper).
+// Hooks are stopped in the reverse order of their dependencies and stages.
+// However, if a hook fails to stop before timeout, the engine stops waiting for it: its goroutines are leaked, and
+// reported once the engine has shutdown (see Report and DumpLeaks).
+// Likewise, the engine shutdowns if hooks aren't ready before their startup timeout (see StartupTimeout).
+// On repeated signals, the shutdown could also be forced (see Escalate).
+//
+// Reload
+//
+// When the engine receives a SIGHUP signal, or when Reload is called, every running hook that implements Reloader
+// is reloaded without being restarted.
+//
+// Upgrade
+//
+// When Upgrade is called, or when the engine receives the signal defined with UpgradeSignal, the binary is executed
+// again and sockets of hooks are handed off to the new process. Then, the engine shutdowns once the new one is ready.
 //
 type Engine struct {
 	interrupt      chan os.Signal
 	timeout        time.Duration
-	hooks          []Hook
-	wait           sync.WaitGroup
+	hooks          []*hookEntry
+	pending        int
+	drained        *sync.Cond
 	parent         context.Context
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -44,6 +64,40 @@ type Engine struct {
 	logger         func(error)
 	mutex          sync.Mutex
 	cause          error
+	ready          chan struct{}
+	stopRequested  bool
+	state          State
+	broker         broker
+	reloads        chan os.Signal
+	reloadSignal   os.Signal
+	noReload       bool
+	reloadPolicy   ReloadPolicy
+	reloading      sync.Mutex
+	systemd        bool
+	sockets        *sockets
+	handoff        *os.File
+	upgrades       chan os.Signal
+	upgradeSignal  os.Signal
+	upgradeTimeout time.Duration
+	upgrading      sync.Mutex
+	upgraded       bool
+	pidPath        string
+	pidFile        *os.File
+	stages         []stage
+	startupTimeout time.Duration
+	escalation     *EscalationPolicy
+	forced         chan struct{}
+	finished       chan struct{}
+	drainDelay     time.Duration
+	started        time.Time
+	stopped        time.Time
+	tracer         Tracer
+	tracing        context.Context
+	startup        func(error)
+	shutdown       func(error)
+	slog           *slog.Logger
+	repanic        bool
+	dumpLeaks      bool
 }
 
 // New creates a new engine with given options.
@@ -53,6 +107,8 @@ func New(ctx context.Context, options ...Option) (*Engine, error) {
 
 	e := &Engine{}
 	e.parent = ctx
+	e.sockets = inherit()
+	e.handoff, e.pidFile = inheritUpgrade()
 	e.init()
 
 	for _, o := range options {
@@ -64,37 +120,92 @@ func New(ctx context.Context, options ...Option) (*Engine, error) {
 	return e, nil
 }
 
-// launch will start given hook.
-func (e *Engine) launch(h Hook) {
+// launch will start given hook once its dependencies are ready.
+// It must be called with the engine's mutex.
+func (e *Engine) launch(h *hookEntry) {
+
+	// Each hook has its own context, so it's cancelled only after every hook that depends on it has shutdown, or
+	// when it's stopped with StopHook(). This context also carries the readiness notifier of the hook, the inherited
+	// sockets, its structured logger and the span of the engine.
+	ctx := context.WithValue(context.WithoutCancel(e.tracing), readyKey{}, h.notify)
+	ctx = context.WithValue(ctx, socketsKey{}, e.sockets)
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, loggerKey{}, e.hookLogger(h)))
 
-	e.wait.Add(1)
+	h.cancel = cancel
+	h.launched = time.Now()
+	e.pending++
+
+	go func() {
+		select {
+		case <-e.ctx.Done():
+			e.waitDependents(h)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
 	go func() {
 
-		defer e.wait.Done()
+		defer e.release()
+		defer close(h.done)
+		defer cancel()
 
-		runtime := &HookRuntime{}
+		// If the hook is shutting down before every dependency is ready, it's never started.
+		if !h.waitDependencies(ctx) {
+			return
+		}
+
+		ctx, started := e.trace(ctx, SpanHookStart, h.name)
+		go func() {
+			select {
+			case <-h.ready:
+				started(nil)
+			case <-ctx.Done():
+			}
+		}()
+
+		runtime := &HookRuntime{
+			name:     h.name,
+			observer: e.observe(h),
+			entered:  e.entered(h),
+			timeout:  e.shutdownTimeout(h),
+			forced:   e.forced,
+			tracker:  h.tracker,
+		}
+		go e.monitor(ctx, h, runtime)
+		go e.expect(ctx, h)
 
 		// Wait for an event to notify this goroutine that a shutdown is required.
 		// It could either be from engine's context or during Hook startup if an error has occurred.
+		// The hook may be restarted by HookRuntime, until its restart policy is exhausted.
 		// NOTE: If HookRuntime returns an error, we have to shutdown every Hook...
-		err := runtime.WaitForEvent(e.ctx, h)
+		err := runtime.Supervise(ctx, h.hook, h.policy, func(err error) {
+			e.failure(h, err)
+		})
+		started(err)
 		if err != nil {
-			e.mutex.Lock()
-			e.log(err)
-			e.cancel()
-			e.cause = err
-			e.mutex.Unlock()
+			e.failure(h, err)
+			e.abort(err)
 		}
 
-		// Wait for hook to gracefully shutdown, or kill it after timeout.
+		e.update(h, StateStopping)
+		stopped := e.traceStop(h)
+
+		// Wait for hook to gracefully shutdown, or stop waiting for it after timeout.
 		// This is handled by HookRuntime.
-		for _, err := range runtime.Shutdown(e.timeout) {
-			e.mutex.Lock()
-			e.log(err)
-			e.mutex.Unlock()
+		failures := runtime.Shutdown(e.shutdownTimeout(h))
+		for _, err := range failures {
+			e.failure(h, err)
+		}
+
+		if len(failures) > 0 {
+			stopped(failures[0])
+		} else {
+			stopped(nil)
 		}
 
+		e.settle(h, err)
+
 	}()
 }
 
@@ -104,56 +215,205 @@ func (e *Engine) init() {
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
+	// Engine's context is only cancelled by waitShutdownNotification, or when a hook has failed, so the trigger of
+	// a shutdown is always known.
 	if e.ctx == nil || e.cancel == nil {
-		e.ctx, e.cancel = context.WithCancel(e.parent)
+		e.ctx, e.cancel = context.WithCancel(context.WithoutCancel(e.parent))
 	}
 
 	if e.timeout == 0 {
 		e.timeout = DefaultTimeout
 	}
 
+	if e.upgradeTimeout == 0 {
+		e.upgradeTimeout = DefaultUpgradeTimeout
+	}
+
+	if e.sockets == nil {
+		e.sockets = &sockets{}
+	}
+
+	if e.tracing == nil {
+		e.tracing = e.ctx
+	}
+
+	e.initSignals()
+	e.initChannels()
+
+}
+
+// initSignals configures the default signals handled by the engine.
+// It must be called with the engine's mutex.
+func (e *Engine) initSignals() {
+
 	if len(e.signals) == 0 && !e.noSignal {
 		e.signals = Signals
 	}
 
+	if e.reloadSignal == nil && !e.noSignal && !e.noReload {
+		e.reloadSignal = DefaultReloadSignal
+	}
+
+}
+
+// initChannels creates the channels, and condition, used to synchronise the engine's lifecycle.
+// It must be called with the engine's mutex.
+func (e *Engine) initChannels() {
+
 	if e.interrupt == nil {
 		e.interrupt = make(chan os.Signal, 1)
 	}
 
+	if e.reloads == nil {
+		e.reloads = make(chan os.Signal, 1)
+	}
+
+	if e.upgrades == nil {
+		e.upgrades = make(chan os.Signal, 1)
+	}
+
+	if e.ready == nil {
+		e.ready = make(chan struct{})
+	}
+
+	if e.drained == nil {
+		e.drained = sync.NewCond(&e.mutex)
+	}
+
+	if e.forced == nil {
+		e.forced = make(chan struct{})
+	}
+
+	if e.finished == nil {
+		e.finished = make(chan struct{})
+	}
+
 }
 
-// Start will launch the engine and start registered hooks.
+// Start will launch the engine and start registered hooks, following their dependencies order.
 // It will block until every hooks has shutdown, gracefully or with force...
+//
+// The error returned is the first one that has triggered the shutdown, if any. Use Run to obtain every error.
+// An error is returned without starting any hook if a dependency is either missing or circular, if a hook has
+// been registered with an invalid option or in an undeclared stage, if the pid file is locked by another instance,
+// or if the engine has already been started.
+// Errors of hooks are HookError, whereas the errors of the engine itself, such as ErrAlreadyStarted or
+// ErrAlreadyRunning, are returned as is.
 func (e *Engine) Start() error {
+	return e.Run().Cause
+}
+
+// Run will launch the engine like Start, and it will return a report of the whole lifecycle once every hook has
+// shutdown. However, if Repanic is defined, it will panic instead if a hook has panicked.
+func (e *Engine) Run() *Report {
 
 	e.init()
 
+	e.mutex.Lock()
+
+	if !e.transition(StateStarting, StateIdle) {
+		e.mutex.Unlock()
+		return &Report{Cause: ErrAlreadyStarted}
+	}
+
+	hooks, err := e.resolve()
+	if err == nil {
+		err = e.acquirePIDFile()
+	}
+	if err != nil {
+		// Engine has not started: its configuration could be fixed.
+		e.transition(StateIdle, StateStarting)
+		e.mutex.Unlock()
+		return &Report{Cause: err}
+	}
+
+	e.started = time.Now()
+	e.traceStartup()
+	notified := e.notifySystemd()
+
+	e.publish(Event{Type: EventEngineStarting})
+
 	go e.waitShutdownNotification()
+	go e.waitReloadNotification()
+	go e.waitUpgradeNotification()
+	go e.waitReady(hooks)
 
-	for _, h := range e.hooks {
+	for _, h := range hooks {
 		e.launch(h)
 	}
 
-	e.wait.Wait()
+	e.mutex.Unlock()
+
+	e.waitHooks()
+	e.sockets.close()
 
 	if e.afterShutdown != nil {
 		e.afterShutdown()
 	}
 
+	close(e.finished)
+
+	e.mutex.Lock()
+	e.releasePIDFile()
+	e.transition(StateStopped, StateStarting, StateRunning, StateStopping)
+	e.stopped = time.Now()
+	report := e.report()
+	e.traceShutdown(report.Cause)
+	e.shutdown(report.Cause)
+	e.mutex.Unlock()
+
+	e.publish(Event{Type: EventEngineStopped, Err: report.Cause, Duration: e.stopped.Sub(e.started)})
+
+	notified()
+
+	if failure := report.panicked(); e.repanic && failure != nil {
+		panic(failure)
+	}
+
+	return report
+
+}
+
+// release is executed once a launched hook has shutdown.
+func (e *Engine) release() {
+
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
-	return e.cause
+	e.pending--
+	e.drained.Broadcast()
+}
 
+// waitHooks will block until every launched hook has shutdown.
+// Then, the engine's context is cancelled, so hooks can't be launched anymore, and waitShutdownNotification is
+// released if every hook has returned by itself.
+func (e *Engine) waitHooks() {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for e.pending > 0 {
+		e.drained.Wait()
+	}
+
+	e.cancel()
 }
 
 // Stop will shutdown engine.
+// An error is returned if the engine has already shutdown.
 func (e *Engine) Stop() error {
 
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
-	if e.interrupt != nil {
+	if e.state == StateStopped {
+		return ErrAlreadyStopped
+	}
+
+	e.stopRequested = true
+
+	// Once the engine is shutting down, an interrupt would be handled as a repeated signal.
+	if e.interrupt != nil && e.state != StateStopping {
 		select {
 		case e.interrupt <- os.Interrupt:
 		default:
@@ -385,7 +385,7 @@ func ShutdownWithHookPanicOnStart(runtime *TestRuntime) {
 		runtime.Error("An error was expected")
 	}
 
-	if err.Error() != "lemon startup failed: Hook has crashed: 0xDEADC0DE" {
+	if err.Error() != "lemon hook *lemon.testHook#2 has panicked: Hook has crashed: 0xDEADC0DE" {
 		runtime.Error("Unexpected error: %s", err)
 	}
 
@@ -0,0 +1,88 @@
+package lemon
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrShutdownTimeout is returned when a hook has not shutdown before its timeout.
+	ErrShutdownTimeout = errors.New("hook has not shutdown before timeout")
+	// ErrStartupTimeout is returned when a hook is not ready before its startup timeout.
+	ErrStartupTimeout = errors.New("hook is not ready before startup timeout")
+)
+
+// Phase is a step of a hook lifecycle.
+type Phase string
+
+const (
+	// PhaseStart is used when a hook has failed to start.
+	PhaseStart = Phase("start")
+	// PhaseStop is used when a hook has failed to stop.
+	PhaseStop = Phase("stop")
+	// PhaseTimeout is used when a hook has not shutdown before its timeout.
+	PhaseTimeout = Phase("timeout")
+	// PhasePanic is used when a hook has panicked.
+	PhasePanic = Phase("panic")
+	// PhasePreStop is used when a hook has failed to prepare its shutdown.
+	PhasePreStop = Phase("prestop")
+	// PhaseCheck is used when a hook has failed its health checks.
+	PhaseCheck = Phase("check")
+	// PhaseReload is used when a hook has failed to reload.
+	PhaseReload = Phase("reload")
+	// PhaseRollback is used when a hook has failed to rollback a reload.
+	PhaseRollback = Phase("rollback")
+)
+
+// HookError is an error that occurs during a hook lifecycle.
+// Every error forwarded to the Logger, except warnings, or returned by the engine's Start(), is a HookError.
+// However, Start() returns errors of the engine itself as is, when it fails to start without any hook: such as
+// ErrAlreadyStarted, or an error of the pid file like ErrAlreadyRunning.
+type HookError struct {
+	// Name is the name of the hook.
+	Name string
+	// Phase is the lifecycle step where the error has occurred.
+	Phase Phase
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *HookError) Error() string {
+	switch e.Phase {
+	case PhaseStart:
+		return fmt.Sprintf("lemon startup failed on hook %s: %s", e.Name, e.Err)
+	case PhaseStop:
+		return fmt.Sprintf("lemon shutdown failed on hook %s: %s", e.Name, e.Err)
+	case PhaseTimeout:
+		return fmt.Sprintf("lemon shutdown timeout on hook %s: %s", e.Name, e.Err)
+	case PhasePanic:
+		return fmt.Sprintf("lemon hook %s has panicked: %s", e.Name, e.Err)
+	case PhasePreStop:
+		return fmt.Sprintf("lemon pre-stop failed on hook %s: %s", e.Name, e.Err)
+	case PhaseCheck:
+		return fmt.Sprintf("lemon health check failed on hook %s: %s", e.Name, e.Err)
+	case PhaseReload:
+		return fmt.Sprintf("lemon reload failed on hook %s: %s", e.Name, e.Err)
+	case PhaseRollback:
+		return fmt.Sprintf("lemon rollback failed on hook %s: %s", e.Name, e.Err)
+	default:
+		return fmt.Sprintf("lemon hook %s has failed: %s", e.Name, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError returns a HookError for given hook and phase, or nil if given error is undefined.
+func wrapError(name string, phase Phase, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &HookError{
+		Name:  name,
+		Phase: phase,
+		Err:   err,
+	}
+}
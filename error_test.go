@@ -0,0 +1,201 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHookError(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Start":     HookErrorOnStart,
+		"Stop":      HookErrorOnStop,
+		"Timeout":   HookErrorOnTimeout,
+		"Panic":     HookErrorOnPanic,
+		"Interface": HookErrorWithNamedInterface,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+type namedHook struct {
+	testHook
+	name string
+}
+
+func (n *namedHook) Name() string {
+	return n.name
+}
+
+// testLogger collects errors forwarded by the engine's logger.
+type testLogger struct {
+	mutex    sync.Mutex
+	failures []error
+}
+
+func (l *testLogger) Handle(err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.failures = append(l.failures, err)
+}
+
+func (l *testLogger) Failures() []error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]error{}, l.failures...)
+}
+
+func (r *TestRuntime) IsHookError(err error, name string, phase Phase, cause error) {
+	failure := &HookError{}
+	if !errors.As(err, &failure) {
+		r.Error("Unexpected error: %v", err)
+	}
+	if failure.Name != name {
+		r.Error("Unexpected hook name: %s", failure.Name)
+	}
+	if failure.Phase != phase {
+		r.Error("Unexpected hook phase: %s", failure.Phase)
+	}
+	if cause != nil && !errors.Is(err, cause) {
+		r.Error("Unexpected cause: %v", failure.Err)
+	}
+}
+
+func HookErrorOnStart(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{startError: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Name("database"))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "database", PhaseStart, hook.startError)
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("Unexpected failures: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "database", PhaseStart, hook.startError)
+
+	runtime.Log("Engine has returned a HookError: %s", err)
+
+}
+
+func HookErrorOnStop(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1), stopError: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Name("database"))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("Unexpected failures: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "database", PhaseStop, hook.stopError)
+
+	runtime.Log("Engine has logged a HookError: %s", failures[0])
+
+}
+
+func HookErrorOnTimeout(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, Logger(logger.Handle), Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}), stopTimeout: true}
+	engine.Register(hook, Name("database"))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("Unexpected failures: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "database", PhaseTimeout, ErrShutdownTimeout)
+
+	runtime.Log("Engine has logged a HookError: %s", failures[0])
+
+}
+
+func HookErrorOnPanic(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{panicOnStart: true}
+	engine.Register(hook, Name("database"))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "database", PhasePanic, nil)
+
+	runtime.Log("Engine has returned a HookError: %s", err)
+
+}
+
+func HookErrorWithNamedInterface(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &namedHook{name: "cache"}
+	hook.startError = errors.New("an error has occurred: foobar")
+	engine.Register(hook)
+
+	err = engine.Start()
+	runtime.IsHookError(err, "cache", PhaseStart, hook.startError)
+
+	runtime.Log("Engine has returned a HookError: %s", err)
+
+}
@@ -0,0 +1,83 @@
+package lemon
+
+import (
+	"errors"
+	"os"
+)
+
+const (
+	// DefaultExitCode is the default exit code of the process when it's terminated by a repeated signal.
+	DefaultExitCode = 130
+)
+
+var (
+	// ErrShutdownForced is returned when a hook has not shutdown before a forced shutdown.
+	ErrShutdownForced = errors.New("hook has not shutdown before forced shutdown")
+)
+
+// exit terminates the process with given exit code.
+var exit = os.Exit
+
+// EscalationPolicy defines how the engine reacts to repeated signals while it's shutting down.
+//
+// The signal that triggers the shutdown is handled gracefully. Once the engine is shutting down, the next signal
+// forces the shutdown: the engine stops waiting for hooks to gracefully shut down, and every hook still running is
+// forwarded to the Logger with ErrShutdownForced. Then, if Exit is enabled, another signal terminates the process
+// right away with ExitCode, even if callbacks such as AfterShutdown are still running.
+type EscalationPolicy struct {
+	Exit     bool
+	ExitCode int
+}
+
+// Escalate enables an escalating shutdown on repeated signals, with given policy.
+// Otherwise, repeated signals are ignored once the engine is shutting down.
+func Escalate(policy EscalationPolicy) Option {
+	return wrapOption(func(e *Engine) error {
+
+		if policy.ExitCode == 0 {
+			policy.ExitCode = DefaultExitCode
+		}
+
+		e.escalation = &policy
+		return nil
+
+	})
+}
+
+// escalate will react to repeated signals, following the escalation policy, until the engine has shutdown.
+func (e *Engine) escalate() {
+
+	count := 0
+
+	for {
+		select {
+		case sig := <-e.interrupt:
+
+			if e.escalation == nil {
+				continue
+			}
+
+			count++
+
+			switch {
+			case count == 1:
+				e.force(sig)
+			case e.escalation.Exit:
+				exit(e.escalation.ExitCode)
+			}
+
+		case <-e.finished:
+			return
+		}
+	}
+}
+
+// force stops waiting for hooks to gracefully shut down.
+func (e *Engine) force(sig os.Signal) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	close(e.forced)
+	e.publish(Event{Type: EventShutdownForced, Signal: sig})
+}
@@ -0,0 +1,169 @@
+package lemon
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEscalate(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Force":   EscalateForce,
+		"Exit":    EscalateExit,
+		"Ignored": EscalateIgnored,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// Interrupt sends given number of signals to the engine once it's ready, with a delay between each one.
+func (r *TestRuntime) Interrupt(engine *Engine, count int, delay time.Duration) {
+
+	err := engine.WaitReady(r.Context())
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		engine.interrupt <- syscall.SIGINT
+	}
+}
+
+func EscalateForce(runtime *TestRuntime) {
+
+	delay := 100 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(3*time.Second), Logger(logger.Handle),
+		Escalate(EscalationPolicy{}))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}), stopTimeout: true}
+
+	engine.Register(hook1)
+	engine.Register(hook2, Name("stuck"))
+
+	result := runtime.StartEngine(engine)
+	runtime.Interrupt(engine, 2, delay)
+
+	now := time.Now()
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InDelta(delta, epsilon, "Engine took way too long to shutdown")
+	runtime.HasLifecycle(hook1, "hook1")
+	runtime.HasEvents(collect(events), EventShutdownRequested, EventShutdownForced, EventEngineStopped)
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("A failure was expected: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "stuck", PhaseTimeout, ErrShutdownForced)
+
+	runtime.Log("Engine has forced its shutdown on a repeated signal: %s", failures[0])
+
+}
+
+func EscalateExit(runtime *TestRuntime) {
+
+	codes := make(chan int, 1)
+
+	previous := exit
+	exit = func(code int) {
+		codes <- code
+	}
+	defer func() {
+		exit = previous
+	}()
+
+	code := 0
+
+	// Hold the shutdown, so the third signal is received before the engine has shutdown.
+	engine, err := New(runtime.Context(), DisableSignal(), Escalate(EscalationPolicy{Exit: true, ExitCode: 42}),
+		AfterShutdown(func() {
+			code = <-codes
+		}))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+	runtime.Interrupt(engine, 3, 20*time.Millisecond)
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if code != 42 {
+		runtime.Error("Unexpected exit code: %d", code)
+	}
+
+	runtime.Log("Engine has terminated the process on a third signal.")
+
+}
+
+func EscalateIgnored(runtime *TestRuntime) {
+
+	timeout := 300 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(timeout), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}), stopTimeout: true})
+
+	result := runtime.StartEngine(engine)
+	runtime.Interrupt(engine, 2, 20*time.Millisecond)
+
+	now := time.Now()
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InEpsilon(delta, timeout, epsilon, "Engine has shutdown with an unexpected amount of time...")
+
+	failures := logger.Failures()
+	if len(failures) != 1 || !errors.Is(failures[0], ErrShutdownTimeout) {
+		runtime.Error("A timeout was expected: %+v", failures)
+	}
+
+	runtime.Log("Engine has ignored a repeated signal without escalation.")
+
+}
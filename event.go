@@ -0,0 +1,170 @@
+package lemon
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of a lifecycle event.
+type EventType string
+
+const (
+	// EventEngineStarting is published when the engine starts.
+	EventEngineStarting = EventType("engine.starting")
+	// EventHookStarting is published when a hook starts, or restarts.
+	EventHookStarting = EventType("hook.starting")
+	// EventHookReady is published when a hook is ready.
+	EventHookReady = EventType("hook.ready")
+	// EventHookFailed is published when an error occurs during a hook lifecycle.
+	EventHookFailed = EventType("hook.failed")
+	// EventReloadRequested is published when a reload of hooks is required.
+	EventReloadRequested = EventType("reload.requested")
+	// EventHookReloaded is published when a hook has been reloaded.
+	EventHookReloaded = EventType("hook.reloaded")
+	// EventUpgradeRequested is published when an upgrade of the process is required.
+	EventUpgradeRequested = EventType("upgrade.requested")
+	// EventUpgradeFailed is published when the new process has failed to be ready on upgrade.
+	EventUpgradeFailed = EventType("upgrade.failed")
+	// EventShutdownRequested is published when the engine has to shutdown.
+	EventShutdownRequested = EventType("shutdown.requested")
+	// EventShutdownForced is published when the engine stops waiting for hooks to gracefully shut down.
+	EventShutdownForced = EventType("shutdown.forced")
+	// EventHookStopped is published when a hook has shutdown.
+	EventHookStopped = EventType("hook.stopped")
+	// EventHookTimeout is published when a hook has not shutdown before its timeout.
+	EventHookTimeout = EventType("hook.timeout")
+	// EventEngineStopped is published when every hook has shutdown.
+	EventEngineStopped = EventType("engine.stopped")
+)
+
+// Trigger is the reason of a shutdown.
+type Trigger string
+
+const (
+	// TriggerSignal is used when the engine has received a signal.
+	TriggerSignal = Trigger("signal")
+	// TriggerContext is used when the parent context of the engine is terminated.
+	TriggerContext = Trigger("context")
+	// TriggerStop is used when the engine's Stop() has been called.
+	TriggerStop = Trigger("stop")
+	// TriggerFailure is used when a hook has failed.
+	TriggerFailure = Trigger("failure")
+	// TriggerUpgrade is used when the process has been replaced by a new one, with Upgrade().
+	TriggerUpgrade = Trigger("upgrade")
+)
+
+// Event is a notification of the engine's lifecycle.
+type Event struct {
+	// Type is the kind of event.
+	Type EventType
+	// Time is when the event has occurred.
+	Time time.Time
+	// Hook is the name of the hook, if the event concerns a hook.
+	Hook string
+	// Err is the error of the hook, or the cause of the shutdown, if any.
+	Err error
+	// Trigger is the reason of a shutdown, for EventShutdownRequested.
+	Trigger Trigger
+	// Signal is the received signal, if the shutdown, or reload, has been triggered by a signal.
+	Signal os.Signal
+	// Duration is the time taken by a hook to be ready, or to shutdown, and the engine's uptime once stopped.
+	Duration time.Duration
+}
+
+// broker forwards events to subscribers without blocking.
+type broker struct {
+	mutex       sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// Subscribe returns a channel that will receive lifecycle events of the engine, with a function to unsubscribe.
+//
+// The channel has a buffer of given size. Events are never blocking the engine: if a subscriber is too slow and
+// its buffer is full, events are dropped for this subscriber.
+// Once unsubscribed, the channel is closed.
+func (e *Engine) Subscribe(size int) (<-chan Event, func()) {
+
+	if size < 0 {
+		size = 0
+	}
+
+	events := make(chan Event, size)
+
+	e.broker.mutex.Lock()
+	defer e.broker.mutex.Unlock()
+
+	if e.broker.subscribers == nil {
+		e.broker.subscribers = map[chan Event]struct{}{}
+	}
+	e.broker.subscribers[events] = struct{}{}
+
+	once := &sync.Once{}
+	unsubscribe := func() {
+		once.Do(func() {
+			e.broker.mutex.Lock()
+			defer e.broker.mutex.Unlock()
+			delete(e.broker.subscribers, events)
+			close(events)
+		})
+	}
+
+	return events, unsubscribe
+}
+
+// publish forwards given event to every subscriber.
+func (e *Engine) publish(event Event) {
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	e.logEvent(event)
+
+	e.broker.mutex.Lock()
+	defer e.broker.mutex.Unlock()
+
+	for events := range e.broker.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// entered returns a callback executed once the goroutine of given hook's Start() is running: a hook that doesn't
+// report its readiness is then ready.
+func (e *Engine) entered(h *hookEntry) func() {
+	return func() {
+		if !h.reportsReady {
+			h.notify()
+		}
+	}
+}
+
+// observe returns a handler which publishes events of given hook.
+// Also, it updates the hook state when it starts.
+func (e *Engine) observe(h *hookEntry) func(EventType, error) {
+	return func(kind EventType, err error) {
+
+		if kind == EventHookStarting {
+			e.starting(h)
+		}
+
+		event := Event{
+			Type: kind,
+			Hook: h.name,
+			Err:  err,
+		}
+
+		if kind == EventHookStopped || kind == EventHookTimeout {
+			e.mutex.Lock()
+			if !h.stopping.IsZero() {
+				event.Duration = time.Since(h.stopping)
+			}
+			e.mutex.Unlock()
+		}
+
+		e.publish(event)
+	}
+}
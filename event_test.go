@@ -0,0 +1,274 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle":   EventLifecycle,
+		"Stop":        EventTriggerStop,
+		"Failure":     EventTriggerFailure,
+		"Timeout":     EventHookTimeoutNotification,
+		"Slow":        EventSlowSubscriber,
+		"Unsubscribe": EventUnsubscribe,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// collect drains given channel until the engine has stopped.
+func collect(events <-chan Event) []Event {
+	list := []Event{}
+	for event := range events {
+		list = append(list, event)
+		if event.Type == EventEngineStopped {
+			return list
+		}
+	}
+	return list
+}
+
+func (r *TestRuntime) HasEvents(events []Event, expected ...EventType) {
+	i := 0
+	for _, event := range events {
+		if i < len(expected) && event.Type == expected[i] {
+			i++
+		}
+	}
+	if i != len(expected) {
+		r.Error("Events %v should have been published in order: %+v", expected, events)
+	}
+}
+
+func (r *TestRuntime) FindEvent(events []Event, kind EventType) Event {
+	for _, event := range events {
+		if event.Type == kind {
+			return event
+		}
+	}
+	r.Error("Event %s should have been published: %+v", kind, events)
+	return Event{}
+}
+
+func EventLifecycle(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook"))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	list := collect(events)
+
+	runtime.HasEvents(list,
+		EventEngineStarting,
+		EventHookStarting,
+		EventShutdownRequested,
+		EventHookStopped,
+		EventEngineStopped,
+	)
+	runtime.HasEvents(list, EventHookReady, EventShutdownRequested)
+
+	for _, kind := range []EventType{EventHookStarting, EventHookReady, EventHookStopped} {
+		event := runtime.FindEvent(list, kind)
+		if event.Hook != "hook" {
+			runtime.Error("Unexpected hook for event %s: %s", kind, event.Hook)
+		}
+		if event.Time.IsZero() {
+			runtime.Error("Event %s should have a timestamp", kind)
+		}
+	}
+
+	event := runtime.FindEvent(list, EventShutdownRequested)
+	if event.Trigger != TriggerContext {
+		runtime.Error("Unexpected trigger: %s", event.Trigger)
+	}
+
+	runtime.Log("Engine has published lifecycle events.")
+
+}
+
+func EventTriggerStop(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		engine.Stop()
+	}()
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	event := runtime.FindEvent(collect(events), EventShutdownRequested)
+	if event.Trigger != TriggerStop {
+		runtime.Error("Unexpected trigger: %s", event.Trigger)
+	}
+
+	runtime.Log("Engine has published a shutdown requested by Stop().")
+
+}
+
+func EventTriggerFailure(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	hook := &testHook{startError: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Name("hook"))
+
+	err = engine.Start()
+	if err == nil {
+		runtime.Error("An error was expected")
+	}
+
+	list := collect(events)
+	runtime.HasEvents(list, EventHookFailed, EventEngineStopped)
+
+	failure := runtime.FindEvent(list, EventHookFailed)
+	runtime.IsHookError(failure.Err, "hook", PhaseStart, hook.startError)
+
+	event := runtime.FindEvent(list, EventShutdownRequested)
+	if event.Trigger != TriggerFailure {
+		runtime.Error("Unexpected trigger: %s", event.Trigger)
+	}
+	if !errors.Is(event.Err, hook.startError) {
+		runtime.Error("Unexpected cause: %v", event.Err)
+	}
+
+	runtime.Log("Engine has published a shutdown requested by a failure.")
+
+}
+
+func EventHookTimeoutNotification(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	engine.Register(&testHook{kill: make(chan struct{}), stopTimeout: true}, Name("hook"))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	event := runtime.FindEvent(collect(events), EventHookTimeout)
+	if event.Hook != "hook" {
+		runtime.Error("Unexpected hook: %s", event.Hook)
+	}
+
+	runtime.Log("Engine has published a hook timeout.")
+
+}
+
+func EventSlowSubscriber(runtime *TestRuntime) {
+
+	kill := 50 * time.Millisecond
+	maximum := kill + 20*time.Millisecond
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	// This subscriber never reads its events.
+	_, unsubscribe := engine.Subscribe(0)
+	defer unsubscribe()
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	now := time.Now()
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.InDelta(time.Since(now), maximum, "Engine took way too long to shutdown")
+
+	runtime.Log("Engine wasn't blocked by a slow subscriber.")
+
+}
+
+func EventUnsubscribe(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(1)
+	unsubscribe()
+	unsubscribe()
+
+	engine.publish(Event{Type: EventEngineStarting})
+
+	if _, ok := <-events; ok {
+		runtime.Error("Channel should be closed")
+	}
+
+	runtime.Log("Subscriber has unsubscribed from engine events.")
+
+}
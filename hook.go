@@ -2,11 +2,27 @@ package lemon
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrHookNotFound is returned when a hook isn't registered on the engine.
+	ErrHookNotFound = errors.New("invalid hook: hook is not registered")
+	// ErrHookRequired is returned when a hook is stopped while another hook that depends on it is still running.
+	ErrHookRequired = errors.New("invalid hook: another hook depends on it")
+	// ErrHookDuplicate is returned when a hook is registered with the name of another hook.
+	ErrHookDuplicate = errors.New("invalid hook: another hook has the same name")
 )
 
 // Hook defines a lifecycle mecanism for a component.
 // If at least one Hook return an error with Start(), it will shutdown the engine.
 // Either every Hook succeed to start, or none of them will...
+//
+// A Hook is considered ready as soon as its Start() is executed, unless it's registered with ReportsReady().
+// In that case, it has to call Ready() with the context given to Start() once it's actually serving.
 type Hook interface {
 	// Start is executed by runtime when a Hook should start.
 	Start(context.Context) error
@@ -16,12 +32,295 @@ type Hook interface {
 	Stop(context.Context) error
 }
 
+// Named is an optional interface for a Hook which defines its own name.
+// Otherwise, a hook is named after its type, and hooks of the same type are numbered, such as "*app.Server#2".
+type Named interface {
+	// Name returns the name of the hook, used to identify it in errors.
+	Name() string
+}
+
+// Name sets the name of the registered hook, used to identify it in errors.
+// It overrides the Named interface. If another hook has the same name, the engine will fail to start with
+// ErrHookDuplicate.
+func Name(name string) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+		if name != "" {
+			h.name = name
+		}
+	})
+}
+
+// hookEntry is a registered Hook, along with its options and the channels used to synchronise its lifecycle with
+// the other hooks.
+type hookEntry struct {
+	hook Hook
+	// name identifies the hook in errors.
+	name string
+	// dependencies are hooks that must be ready before this hook starts.
+	dependencies []Hook
+	// requires is the resolved entries of dependencies.
+	requires []*hookEntry
+	// dependents is the resolved entries that depends on this hook.
+	dependents []*hookEntry
+	// timeout is the maximum amount of time the engine will wait for the hook to shutdown, if defined.
+	timeout time.Duration
+	// readyTimeout is the maximum amount of time the engine will wait for the hook to be ready, if defined.
+	readyTimeout time.Duration
+	// stage is the startup stage of the hook, if defined.
+	stage string
+	// budget is the shutdown timeout of the hook stage, if defined.
+	budget time.Duration
+	// err is an error returned by an option, which prevents the engine from starting.
+	err error
+	// check defines how the hook health is checked, if it implements Checker.
+	check CheckPolicy
+	// policy defines if the hook should be restarted when its Start() returns.
+	policy RestartPolicy
+	// reportsReady defines if the hook reports its readiness with Ready(), instead of being ready once started.
+	reportsReady bool
+	// late defines if the hook wasn't ready before its startup timeout.
+	late bool
+	// ready is closed when the hook is ready.
+	ready chan struct{}
+	// notify closes ready, and can be called more than once.
+	notify func()
+	// done is closed when the hook has shutdown.
+	done chan struct{}
+	// cancel stops the hook, once it has been launched.
+	cancel context.CancelFunc
+	// failures contains every error that has occurred during the hook lifecycle.
+	failures []error
+	// state is the current state of the hook.
+	state State
+	// since is when the hook has entered its current state.
+	since time.Time
+	// restarts is the number of times the hook has been restarted.
+	restarts int
+	// launched is when the hook has been launched, before waiting for its dependencies.
+	launched time.Time
+	// first is when the hook has started for the first time.
+	first time.Time
+	// started is when the hook has started for the last time.
+	started time.Time
+	// readied is when the hook has been ready since its last start.
+	readied time.Time
+	// stopping is when the hook has been asked to shutdown.
+	stopping time.Time
+	// stopped is when the hook has shutdown.
+	stopped time.Time
+	// tracker records goroutines executing Start() and Stop() of the hook, even after its shutdown.
+	tracker *tracker
+}
+
+// reset creates the channels used to synchronise the hook lifecycle.
+// The given callback is executed whenever the hook reports its readiness.
+func (h *hookEntry) reset(callback func()) {
+
+	ready := make(chan struct{})
+	once := &sync.Once{}
+
+	h.ready = ready
+	h.notify = func() {
+		once.Do(func() {
+			close(ready)
+		})
+		callback()
+	}
+	h.done = make(chan struct{})
+	h.failures = nil
+	h.restarts = 0
+	h.launched = time.Time{}
+	h.first = time.Time{}
+	h.started = time.Time{}
+	h.readied = time.Time{}
+	h.stopping = time.Time{}
+	h.stopped = time.Time{}
+	h.transition(StateIdle)
+}
+
+// isReady returns if the hook has reported its readiness.
+func (h *hookEntry) isReady() bool {
+	select {
+	case <-h.ready:
+		return true
+	default:
+		return false
+	}
+}
+
 // Register will attach the given hook on engine's lifecycle mechanism.
-func (e *Engine) Register(hook Hook) {
-	e.hooks = append(e.hooks, hook)
+// Options can declare the hook dependencies and many other behaviours.
+//
+// It's safe to register a hook at any time: if the engine is already starting or running, the hook is started
+// right away, once its dependencies are ready. In that case, if the hook has an invalid option or a missing
+// dependency, it's not started and the error is forwarded to the Logger.
+func (e *Engine) Register(hook Hook, options ...HookOption) {
+
+	entry := &hookEntry{
+		hook:    hook,
+		state:   StateIdle,
+		since:   time.Now(),
+		tracker: newTracker(),
+	}
+
+	if n, ok := hook.(Named); ok && n.Name() != "" {
+		entry.name = n.Name()
+	}
+
+	if d, ok := hook.(Dependent); ok {
+		entry.dependencies = append(entry.dependencies, d.Dependencies()...)
+	}
+
+	if g, ok := hook.(Graceful); ok && g.ShutdownTimeout() > 0 {
+		entry.timeout = g.ShutdownTimeout()
+	}
+
+	for _, o := range options {
+		o.apply(entry)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.rename(entry)
+	e.hooks = append(e.hooks, entry)
+
+	if e.state != StateStarting && e.state != StateRunning {
+		return
+	}
+
+	e.prepare(entry)
+
+	err := e.link(entry)
+	if err != nil {
+		e.log(err)
+		entry.failures = append(entry.failures, err)
+		entry.transition(StateFailed)
+		e.publish(Event{Type: EventHookFailed, Hook: entry.name, Err: err})
+		return
+	}
+
+	e.launch(entry)
+}
+
+// rename ensures that given hook has a name that identifies it among registered hooks.
+// A hook without name is named after its type: hooks of the same type are then numbered, such as "*app.Server"
+// and "*app.Server#2". Otherwise, a duplicate name is an error.
+// It must be called with the engine's mutex.
+func (e *Engine) rename(h *hookEntry) {
+
+	if h.name != "" {
+		if e.findName(h.name) != nil && h.err == nil {
+			h.err = ErrHookDuplicate
+		}
+		return
+	}
+
+	h.name = fmt.Sprintf("%T", h.hook)
+	for i := 2; e.findName(h.name) != nil; i++ {
+		h.name = fmt.Sprintf("%T#%d", h.hook, i)
+	}
+}
+
+// findName returns the registered hook with given name, if any.
+// It must be called with the engine's mutex.
+func (e *Engine) findName(name string) *hookEntry {
+	for _, h := range e.hooks {
+		if h.name == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// StopHook will gracefully shutdown the given hook, without stopping the engine or any other hook.
+// It blocks until the hook has shutdown, or until its shutdown timeout has expired.
+//
+// An error is returned if the hook isn't registered, if the engine hasn't been started, or if another hook that
+// depends on this one is still running: it must be stopped first. Once every hook has shutdown, the engine stops.
+func (e *Engine) StopHook(hook Hook) error {
+
+	e.mutex.Lock()
+
+	h := e.find(hook)
+	if h == nil {
+		e.mutex.Unlock()
+		return ErrHookNotFound
+	}
+
+	if h.cancel == nil {
+		e.mutex.Unlock()
+		return ErrNotRunning
+	}
+
+	for _, dependent := range h.dependents {
+		if dependent.isRunning() {
+			e.mutex.Unlock()
+			return wrapError(h.name, PhaseStop, ErrHookRequired)
+		}
+	}
+
+	h.cancel()
+	e.mutex.Unlock()
+
+	<-h.done
+	return nil
+}
+
+// Unregister will detach the given hook from engine's lifecycle mechanism.
+// If the engine has been started, the hook is stopped beforehand, like with StopHook.
+func (e *Engine) Unregister(hook Hook) error {
+
+	err := e.StopHook(hook)
+	if err != nil && err != ErrNotRunning {
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, h := range e.hooks {
+		if h.hook != hook {
+			continue
+		}
+
+		// A hook could be a dependent of a hook it doesn't require, if it has been registered in a previous stage.
+		e.hooks = append(e.hooks[:i], e.hooks[i+1:]...)
+		for _, other := range e.hooks {
+			other.dependents = remove(other.dependents, h)
+		}
+		return nil
+	}
+
+	return ErrHookNotFound
+}
+
+// isRunning returns if the hook has been launched and hasn't shutdown yet.
+// It must be called with the engine's mutex.
+func (h *hookEntry) isRunning() bool {
+	if h.cancel == nil {
+		return false
+	}
+	select {
+	case <-h.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// remove returns given list without given entry.
+func remove(list []*hookEntry, entry *hookEntry) []*hookEntry {
+	result := make([]*hookEntry, 0, len(list))
+	for _, h := range list {
+		if h != entry {
+			result = append(result, h)
+		}
+	}
+	return result
 }
 
-// BeforeShutdown will register a callback to execute when the engine will shutdown.
+// BeforeShutdown will register a callback to execute when the engine will shutdown, once the pre-stop phase is done.
 func BeforeShutdown(callback func()) Option {
 	return wrapOption(func(e *Engine) error {
 		e.beforeShutdown = callback
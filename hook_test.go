@@ -2,6 +2,7 @@ package lemon
 
 import (
 	"context"
+	"errors"
 	"os"
 	"sync/atomic"
 	"syscall"
@@ -16,6 +17,13 @@ func TestHook(t *testing.T) {
 		"AfterShutdown/Context":  HookAfterShutdownWithContext,
 		"BeforeShutdown/Signal":  HookBeforeShutdownWithSignal,
 		"AfterShutdown/Signal":   HookAfterShutdownWithSignal,
+		"Register/Running":       HookRegisterWhileRunning,
+		"Register/Invalid":       HookRegisterInvalidWhileRunning,
+		"Register/SameType":      HookRegisterSameType,
+		"Register/Duplicate":     HookRegisterDuplicate,
+		"StopHook":               HookStopHook,
+		"StopHook/Required":      HookStopHookRequired,
+		"Unregister":             HookUnregister,
 	}
 
 	for name, handler := range tests {
@@ -222,3 +230,349 @@ func HookAfterShutdownWithSignal(runtime *TestRuntime) {
 	runtime.Log("Engine has executed AfterShutdown hook.")
 
 }
+
+func (r *TestRuntime) WaitHookState(engine *Engine, name string, expected State) {
+	for i := 0; i < 100; i++ {
+		for _, h := range engine.Hooks() {
+			if h.Name == name && h.State == expected {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.Error("Hook %s should be %s: %+v", name, expected, engine.Hooks())
+}
+
+func HookRegisterWhileRunning(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Register(hook2, Name("hook2"), DependsOn(hook1))
+	runtime.WaitHookState(engine, "hook2", StateRunning)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(hook1, "hook1")
+	runtime.HasLifecycle(hook2, "hook2")
+	runtime.HasHookStates(engine, StateStopped, StateStopped)
+
+	runtime.Log("Engine has started a hook registered while running.")
+
+}
+
+func HookRegisterInvalidWhileRunning(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Register(hook2, Name("hook2"), DependsOn(&testHook{}))
+	runtime.HasHookStates(engine, StateRunning, StateFailed)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("A failure was expected: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "hook2", PhaseStart, ErrMissingDependency)
+
+	hook2.mutex.Lock()
+	if hook2.startCalled {
+		runtime.Error("Hook2 shouldn't have started")
+	}
+	hook2.mutex.Unlock()
+
+	runtime.Log("Engine has refused a hook with a missing dependency while running.")
+
+}
+
+func HookRegisterSameType(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+	hook3 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1)
+	engine.Register(hook2)
+	engine.Register(hook3)
+
+	hooks := engine.Hooks()
+	expected := []string{"*lemon.testHook", "*lemon.testHook#2", "*lemon.testHook#3"}
+	if len(hooks) != len(expected) {
+		runtime.Error("Unexpected hooks: %+v", hooks)
+	}
+	for i := range expected {
+		if hooks[i].Name != expected[i] {
+			runtime.Error("Unexpected hook name: %s", hooks[i].Name)
+		}
+	}
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.StopHook(hook2)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	runtime.HasHookStates(engine, StateRunning, StateStopped, StateRunning)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has given a distinct name to hooks of the same type.")
+
+}
+
+func HookRegisterDuplicate(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("server"))
+	engine.Register(hook2, Name("server"))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "server", PhaseStart, ErrHookDuplicate)
+
+	hook2.mutex.Lock()
+	if hook2.startCalled {
+		runtime.Error("Hook2 shouldn't have started")
+	}
+	hook2.mutex.Unlock()
+
+	runtime.Log("Engine has refused a hook with a duplicate name.")
+
+}
+
+func HookStopHook(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"))
+
+	err = engine.StopHook(hook2)
+	if err != ErrNotRunning {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.StopHook(hook2)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(hook2, "hook2")
+	runtime.HasHookStates(engine, StateRunning, StateStopped)
+
+	if engine.State() != StateRunning {
+		runtime.Error("Engine should still be running: %s", engine.State())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(hook1, "hook1")
+
+	runtime.Log("Engine has stopped a single hook.")
+
+}
+
+func HookStopHookRequired(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"), DependsOn(hook1))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.StopHook(hook1)
+	runtime.IsHookError(err, "hook1", PhaseStop, ErrHookRequired)
+
+	err = engine.StopHook(hook2)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.StopHook(hook1)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	// Every hook has shutdown, so the engine stops.
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(hook1, "hook1")
+	runtime.HasLifecycle(hook2, "hook2")
+
+	runtime.Log("Engine has stopped hooks in the reverse order of their dependencies.")
+
+}
+
+func HookUnregister(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+	hook3 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"), DependsOn(hook1))
+	engine.Register(hook3, Name("hook3"))
+
+	err = engine.Unregister(hook3)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Unregister(hook2)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasLifecycle(hook2, "hook2")
+	runtime.HasHookStates(engine, StateRunning)
+
+	err = engine.Unregister(hook2)
+	if !errors.Is(err, ErrHookNotFound) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook3.mutex.Lock()
+	if hook3.startCalled {
+		runtime.Error("Hook3 shouldn't have started")
+	}
+	hook3.mutex.Unlock()
+
+	runtime.Log("Engine has unregistered hooks.")
+
+}
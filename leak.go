@@ -0,0 +1,129 @@
+package lemon
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// tracker records the goroutines executing Start() and Stop() of a hook, so they can be reported if they are
+// leaked: when they are still running once the hook has shutdown, after its timeout.
+type tracker struct {
+	mutex      sync.Mutex
+	goroutines map[uint64]struct{}
+}
+
+// newTracker creates a tracker without any goroutine.
+func newTracker() *tracker {
+	return &tracker{goroutines: map[uint64]struct{}{}}
+}
+
+// track records the calling goroutine, until the returned function is executed.
+func (t *tracker) track() func() {
+
+	if t == nil {
+		return func() {}
+	}
+
+	id := goroutine()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.goroutines[id] = struct{}{}
+
+	return func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+
+		delete(t.goroutines, id)
+	}
+}
+
+// leaked returns if a recorded goroutine is still running.
+func (t *tracker) leaked() bool {
+
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return len(t.goroutines) > 0
+}
+
+// dump returns the stack trace of every recorded goroutine which is still running.
+func (t *tracker) dump() []byte {
+
+	if !t.leaked() {
+		return nil
+	}
+
+	buffer := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) {
+			buffer = buffer[:n]
+			break
+		}
+		buffer = make([]byte, 2*len(buffer))
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stacks := [][]byte{}
+	for _, stack := range bytes.Split(buffer, []byte("\n\n")) {
+		if _, ok := t.goroutines[parseGoroutine(stack)]; ok {
+			stacks = append(stacks, stack)
+		}
+	}
+
+	return bytes.Join(stacks, []byte("\n\n"))
+}
+
+// goroutine returns the identifier of the calling goroutine.
+func goroutine() uint64 {
+	buffer := make([]byte, 64)
+	return parseGoroutine(buffer[:runtime.Stack(buffer, false)])
+}
+
+// parseGoroutine returns the identifier of the goroutine from the header of its stack trace, such as
+// "goroutine 42 [running]:", or zero if it's invalid.
+func parseGoroutine(stack []byte) uint64 {
+
+	fields := bytes.Fields(stack)
+	if len(fields) < 2 || string(fields[0]) != "goroutine" {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+// DumpLeaks will capture the stack trace of every goroutine executing Start() or Stop() of a hook that is still
+// running once the engine has shutdown, after the hook's timeout. The stack traces are available in the Dump of
+// each leaked HookReport. It helps to find hooks that ignore the cancellation of their context.
+func DumpLeaks() Option {
+	return wrapOption(func(e *Engine) error {
+		e.dumpLeaks = true
+		return nil
+	})
+}
+
+// Leaked returns the name of every hook that is still running once the engine has shutdown.
+func (r *Report) Leaked() []string {
+	names := []string{}
+	for _, h := range r.Hooks {
+		if h.Leaked {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,134 @@
+package lemon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLeak(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Report": LeakReport,
+		"Dump":   LeakDump,
+		"Parse":  LeakParse,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+func LeakReport(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1), stopTimeout: true}, Name("database"))
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("cache"))
+
+	result := make(chan *Report, 1)
+	go func() {
+		result <- engine.Run()
+	}()
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	report := <-result
+	if len(report.Hooks) != 2 {
+		runtime.Error("Unexpected report: %+v", report)
+	}
+
+	leaked := report.Leaked()
+	if len(leaked) != 1 || leaked[0] != "database" {
+		runtime.Error("Unexpected leaked hooks: %v", leaked)
+	}
+
+	for _, hook := range report.Hooks {
+		if hook.Name == "database" && (!hook.Leaked || !hook.TimedOut) {
+			runtime.Error("Hook should have been leaked after its timeout: %+v", hook)
+		}
+		if hook.Name == "cache" && hook.Leaked {
+			runtime.Error("Hook shouldn't have been leaked: %+v", hook)
+		}
+		if hook.Dump != nil {
+			runtime.Error("Stack traces shouldn't have been captured: %s", hook.Dump)
+		}
+	}
+
+	runtime.Log("Engine has reported its leaked hooks.")
+
+}
+
+func LeakDump(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(50*time.Millisecond), DumpLeaks())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1), stopTimeout: true}, Name("database"))
+	engine.Register(&readyHook{delay: -1}, Name("cache"))
+
+	result := make(chan *Report, 1)
+	go func() {
+		result <- engine.Run()
+	}()
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	report := <-result
+	for _, hook := range report.Hooks {
+		if hook.Name != "database" {
+			continue
+		}
+
+		// Both Start(), waiting to be killed, and Stop() are still running.
+		if bytes.Count(append([]byte("\n"), hook.Dump...), []byte("\ngoroutine ")) != 2 {
+			runtime.Error("Unexpected stack traces:\n%s", hook.Dump)
+		}
+		if !bytes.Contains(hook.Dump, []byte("lemon.(*testHook).Start")) ||
+			!bytes.Contains(hook.Dump, []byte("lemon.(*testHook).Stop")) {
+			runtime.Error("Stack traces should contain the hook:\n%s", hook.Dump)
+		}
+		if bytes.Contains(hook.Dump, []byte("lemon.(*readyHook)")) {
+			runtime.Error("Stack traces should only contain the leaked hook:\n%s", hook.Dump)
+		}
+	}
+
+	runtime.Log("Engine has captured stack traces of its leaked hooks.")
+
+}
+
+func LeakParse(runtime *TestRuntime) {
+
+	if parseGoroutine([]byte("goroutine 42 [running]:\nmain.main()")) != 42 {
+		runtime.Error("Goroutine identifier should have been parsed")
+	}
+	if parseGoroutine([]byte("invalid")) != 0 || parseGoroutine([]byte("goroutine foo [running]:")) != 0 {
+		runtime.Error("Goroutine identifier should be invalid")
+	}
+	if goroutine() == 0 {
+		runtime.Error("Goroutine identifier should have been found")
+	}
+
+	runtime.Log("Goroutine identifiers have been parsed.")
+
+}
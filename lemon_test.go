@@ -21,6 +21,7 @@ type testHook struct {
 	stopTimeout  bool
 	panicOnStart bool
 	panicOnStop  bool
+	startDelay   time.Duration
 }
 
 func (t *testHook) Start(ctx context.Context) error {
@@ -33,6 +34,7 @@ func (t *testHook) Start(ctx context.Context) error {
 	for t.startTimeout {
 		time.Sleep(100 * time.Millisecond)
 	}
+	time.Sleep(t.startDelay)
 	if t.kill != nil {
 		<-t.kill
 	}
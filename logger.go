@@ -2,6 +2,8 @@ package lemon
 
 // Logger sets an optional error handler.
 // Use this Option if you want to receives errors that occurs during startup or shutdown.
+// Every error is a HookError, which identifies the hook and the lifecycle phase where it has occurred, except
+// warnings such as ErrUnclaimedSocket.
 // Also, an engine's internal mutex avoid race conditions.
 func Logger(handler func(err error)) Option {
 	return wrapOption(func(e *Engine) error {
@@ -15,4 +17,7 @@ func (e *Engine) log(err error) {
 	if err != nil && e.logger != nil {
 		e.logger(err)
 	}
+	if err != nil {
+		e.logWarning(err)
+	}
 }
@@ -44,7 +44,7 @@ func LoggerErrOnStart(runtime *TestRuntime) {
 		runtime.Error("An error was expected")
 	}
 
-	if err != hook.startError {
+	if !errors.Is(err, hook.startError) {
 		runtime.Error("Unexpected failure: %+v", err)
 	}
 
@@ -52,7 +52,7 @@ func LoggerErrOnStart(runtime *TestRuntime) {
 		runtime.Error("Unexpected failures: %+v", failures)
 	}
 
-	if failures[0] != hook.startError {
+	if !errors.Is(failures[0], hook.startError) {
 		runtime.Error("Unexpected failure: %+v", failures[0])
 	}
 
@@ -98,7 +98,7 @@ func LoggerErrOnStop(runtime *TestRuntime) {
 		runtime.Error("Unexpected failures: %+v", failures)
 	}
 
-	if failures[0] != hook.stopError {
+	if !errors.Is(failures[0], hook.stopError) {
 		runtime.Error("Unexpected failure: %+v", failures[0])
 	}
 
@@ -150,7 +150,7 @@ func LoggerErrLifecycle(runtime *TestRuntime) {
 	}
 
 	for _, err := range failures {
-		if err != hook.startError && err != hook.stopError {
+		if !errors.Is(err, hook.startError) && !errors.Is(err, hook.stopError) {
 			runtime.Error("Unexpected failure: %+v", err)
 		}
 	}
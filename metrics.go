@@ -0,0 +1,256 @@
+package lemon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// states contains every state of the engine, or hook, state machine.
+var states = []State{
+	StateIdle,
+	StateStarting,
+	StateRunning,
+	StateUnhealthy,
+	StateStopping,
+	StateStopped,
+	StateFailed,
+}
+
+// metric is a family of samples in the Prometheus text exposition format.
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+// sample is a value of a metric, with its labels.
+type sample struct {
+	labels string
+	value  float64
+}
+
+// add appends a sample with given labels, as name and value pairs.
+func (m *metric) add(value float64, labels ...string) {
+
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], escapeLabel(labels[i+1])))
+	}
+
+	m.samples = append(m.samples, sample{labels: strings.Join(pairs, ","), value: value})
+}
+
+// write writes the metric in the Prometheus text exposition format.
+func (m *metric) write(b *strings.Builder) {
+
+	fmt.Fprintf(b, "# HELP %s %s\n", m.name, m.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", m.name, m.kind)
+
+	for _, s := range m.samples {
+		b.WriteString(m.name)
+		if s.labels != "" {
+			b.WriteString("{" + s.labels + "}")
+		}
+		b.WriteString(" " + strconv.FormatFloat(s.value, 'g', -1, 64) + "\n")
+	}
+}
+
+// escapeLabel escapes a label value for the Prometheus text exposition format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// MetricsHandler returns an http.Handler which exposes metrics of the engine's lifecycle in the Prometheus text
+// exposition format:
+//
+//   - lemon_engine_uptime_seconds: time since the engine has started, until it has stopped.
+//   - lemon_engine_state: current state of the engine, with a sample for each state.
+//   - lemon_hook_state: current state of each hook, with a sample for each state.
+//   - lemon_hook_start_duration_seconds: time taken by each hook to start once launched, including the wait for
+//     its dependencies.
+//   - lemon_hook_ready_duration_seconds: time taken by each hook to be ready since its last start.
+//   - lemon_hook_stop_duration_seconds: time taken by each hook to shutdown, which grows while it's stopping.
+//   - lemon_hook_restarts_total, lemon_hook_panics_total and lemon_hook_timeouts_total: number of restarts,
+//     panics and shutdown timeouts of each hook.
+//
+// Durations are only exposed once they're known.
+func (e *Engine) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		b := &strings.Builder{}
+		for _, m := range e.metrics(time.Now()) {
+			m.write(b)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte(b.String()))
+
+	})
+}
+
+// metrics returns the metrics of the engine and its hooks at given time.
+func (e *Engine) metrics(now time.Time) []*metric {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	uptime := &metric{
+		name: "lemon_engine_uptime_seconds",
+		help: "Time since the engine has started, until it has stopped.",
+		kind: "gauge",
+	}
+	engine := &metric{
+		name: "lemon_engine_state",
+		help: "Current state of the engine.",
+		kind: "gauge",
+	}
+	hooks := newHookMetrics()
+
+	if !e.started.IsZero() {
+		end := now
+		if !e.stopped.IsZero() {
+			end = e.stopped
+		}
+		uptime.add(end.Sub(e.started).Seconds())
+	} else {
+		uptime.add(0)
+	}
+
+	current := e.state
+	if current == "" {
+		current = StateIdle
+	}
+
+	for _, s := range states {
+		if s != StateUnhealthy && s != StateFailed {
+			engine.add(gauge(current == s), "state", string(s))
+		}
+	}
+
+	for _, h := range e.hooks {
+		hooks.add(h, now)
+	}
+
+	return append([]*metric{uptime, engine}, hooks.list()...)
+}
+
+// hookMetrics contains the metrics of every hook.
+type hookMetrics struct {
+	state    *metric
+	start    *metric
+	ready    *metric
+	stop     *metric
+	restarts *metric
+	panics   *metric
+	timeouts *metric
+}
+
+// newHookMetrics creates the metrics of hooks, without any sample.
+func newHookMetrics() *hookMetrics {
+	return &hookMetrics{
+		state: &metric{
+			name: "lemon_hook_state",
+			help: "Current state of the hook.",
+			kind: "gauge",
+		},
+		start: &metric{
+			name: "lemon_hook_start_duration_seconds",
+			help: "Time taken by the hook to start once launched, including the wait for its dependencies.",
+			kind: "gauge",
+		},
+		ready: &metric{
+			name: "lemon_hook_ready_duration_seconds",
+			help: "Time taken by the hook to be ready since its last start.",
+			kind: "gauge",
+		},
+		stop: &metric{
+			name: "lemon_hook_stop_duration_seconds",
+			help: "Time taken by the hook to shutdown.",
+			kind: "gauge",
+		},
+		restarts: &metric{
+			name: "lemon_hook_restarts_total",
+			help: "Number of restarts of the hook.",
+			kind: "counter",
+		},
+		panics: &metric{
+			name: "lemon_hook_panics_total",
+			help: "Number of panics of the hook.",
+			kind: "counter",
+		},
+		timeouts: &metric{
+			name: "lemon_hook_timeouts_total",
+			help: "Number of times the hook has not shutdown before its timeout.",
+			kind: "counter",
+		},
+	}
+}
+
+// list returns the metrics of hooks, in their exposition order.
+func (m *hookMetrics) list() []*metric {
+	return []*metric{m.state, m.start, m.ready, m.stop, m.restarts, m.panics, m.timeouts}
+}
+
+// add appends the samples of given hook at given time.
+// It must be called with the engine's mutex.
+func (m *hookMetrics) add(h *hookEntry, now time.Time) {
+
+	status := h.status()
+	for _, s := range states {
+		m.state.add(gauge(status.State == s), "hook", h.name, "state", string(s))
+	}
+
+	if !h.launched.IsZero() && !h.first.IsZero() {
+		m.start.add(h.first.Sub(h.launched).Seconds(), "hook", h.name)
+	}
+
+	if !h.started.IsZero() && !h.readied.IsZero() {
+		m.ready.add(h.readied.Sub(h.started).Seconds(), "hook", h.name)
+	}
+
+	if !h.stopping.IsZero() {
+		end := now
+		if !h.stopped.IsZero() {
+			end = h.stopped
+		}
+		m.stop.add(end.Sub(h.stopping).Seconds(), "hook", h.name)
+	}
+
+	panics, timeouts := countFailures(h.failures)
+
+	m.restarts.add(float64(h.restarts), "hook", h.name)
+	m.panics.add(float64(panics), "hook", h.name)
+	m.timeouts.add(float64(timeouts), "hook", h.name)
+}
+
+// countFailures returns the number of panics, and the number of shutdown timeouts, in given errors.
+func countFailures(failures []error) (int, int) {
+
+	panics := 0
+	timeouts := 0
+	for _, err := range failures {
+		failure := &HookError{}
+		if errors.As(err, &failure) && failure.Phase == PhasePanic {
+			panics++
+		}
+		if errors.Is(err, ErrShutdownTimeout) {
+			timeouts++
+		}
+	}
+
+	return panics, timeouts
+}
+
+// gauge returns 1 if given condition is true, or 0 otherwise.
+func gauge(condition bool) float64 {
+	if condition {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package lemon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle": MetricsLifecycle,
+		"Failures":  MetricsFailures,
+		"Escape":    MetricsEscape,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// Scrape returns the metrics exposed by given engine.
+func (r *TestRuntime) Scrape(engine *Engine) string {
+
+	recorder := httptest.NewRecorder()
+	engine.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		r.Error("Unexpected status code: %d", recorder.Code)
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		r.Error("Unexpected content type: %s", recorder.Header().Get("Content-Type"))
+	}
+
+	return recorder.Body.String()
+}
+
+// HasMetric returns the value of given series, which must be exposed.
+func (r *TestRuntime) HasMetric(metrics, series string) float64 {
+	for _, line := range strings.Split(metrics, "\n") {
+		if strings.HasPrefix(line, series+" ") {
+			value, err := strconv.ParseFloat(strings.TrimPrefix(line, series+" "), 64)
+			if err != nil {
+				r.Error("Unexpected value for %s: %s", series, line)
+			}
+			return value
+		}
+	}
+	r.Error("Series %s should have been exposed:\n%s", series, metrics)
+	return 0
+}
+
+func (r *TestRuntime) HasNoMetric(metrics, series string) {
+	for _, line := range strings.Split(metrics, "\n") {
+		if strings.HasPrefix(line, series+" ") {
+			r.Error("Series %s shouldn't have been exposed:\n%s", series, metrics)
+		}
+	}
+}
+
+func MetricsLifecycle(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&readyHook{delay: 50 * time.Millisecond}, Name("database"), ReportsReady())
+
+	metrics := runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, "lemon_engine_uptime_seconds") != 0 {
+		runtime.Error("Engine shouldn't have any uptime before it has started")
+	}
+	if runtime.HasMetric(metrics, `lemon_engine_state{state="idle"}`) != 1 {
+		runtime.Error("Engine should be idle")
+	}
+	runtime.HasNoMetric(metrics, `lemon_hook_ready_duration_seconds{hook="database"}`)
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	metrics = runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, `lemon_engine_state{state="running"}`) != 1 {
+		runtime.Error("Engine should be running")
+	}
+	if runtime.HasMetric(metrics, `lemon_hook_state{hook="database",state="running"}`) != 1 {
+		runtime.Error("Hook should be running")
+	}
+	if runtime.HasMetric(metrics, `lemon_hook_state{hook="database",state="starting"}`) != 0 {
+		runtime.Error("Hook shouldn't be starting")
+	}
+
+	ready := runtime.HasMetric(metrics, `lemon_hook_ready_duration_seconds{hook="database"}`)
+	runtime.InEpsilon(time.Duration(ready*float64(time.Second)), 50*time.Millisecond, 30*time.Millisecond,
+		"Hook has been ready with an unexpected amount of time...")
+
+	runtime.HasMetric(metrics, `lemon_hook_start_duration_seconds{hook="database"}`)
+	runtime.HasNoMetric(metrics, `lemon_hook_stop_duration_seconds{hook="database"}`)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	metrics = runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, `lemon_engine_state{state="stopped"}`) != 1 {
+		runtime.Error("Engine should be stopped")
+	}
+
+	uptime := runtime.HasMetric(metrics, "lemon_engine_uptime_seconds")
+	time.Sleep(20 * time.Millisecond)
+	if runtime.HasMetric(runtime.Scrape(engine), "lemon_engine_uptime_seconds") != uptime {
+		runtime.Error("Engine uptime shouldn't grow once stopped")
+	}
+
+	runtime.HasMetric(metrics, `lemon_hook_stop_duration_seconds{hook="database"}`)
+
+	runtime.Log("Engine has exposed its lifecycle metrics.")
+
+}
+
+func MetricsFailures(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{panicOnStart: true}, Name("cache"))
+	engine.Register(&testHook{kill: make(chan struct{}), stopTimeout: true}, Name("database"))
+
+	err = engine.Start()
+	failure := &HookError{}
+	if !errors.As(err, &failure) || failure.Phase != PhasePanic {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	metrics := runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, `lemon_hook_panics_total{hook="cache"}`) != 1 {
+		runtime.Error("Hook should have panicked once")
+	}
+	if runtime.HasMetric(metrics, `lemon_hook_state{hook="cache",state="failed"}`) != 1 {
+		runtime.Error("Hook should have failed")
+	}
+	if runtime.HasMetric(metrics, `lemon_hook_timeouts_total{hook="database"}`) != 1 {
+		runtime.Error("Hook should have timed out once")
+	}
+	if runtime.HasMetric(metrics, `lemon_hook_restarts_total{hook="database"}`) != 0 {
+		runtime.Error("Hook shouldn't have been restarted")
+	}
+
+	runtime.Log("Engine has exposed failures of its hooks.")
+
+}
+
+func MetricsEscape(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{}, Name("my \"hook\"\\\n"))
+
+	metrics := runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, `lemon_hook_state{hook="my \"hook\"\\\n",state="idle"}`) != 1 {
+		runtime.Error("Hook should be idle")
+	}
+
+	runtime.Log("Engine has escaped the name of its hooks.")
+
+}
@@ -16,3 +16,20 @@ func (o option) apply(e *Engine) error {
 func wrapOption(f func(*Engine) error) Option {
 	return option{f}
 }
+
+// HookOption is used to set options for a hook when it's registered on the engine.
+type HookOption interface {
+	apply(*hookEntry)
+}
+
+type hookOption struct {
+	callback func(*hookEntry)
+}
+
+func (o hookOption) apply(h *hookEntry) {
+	o.callback(h)
+}
+
+func wrapHookOption(f func(*hookEntry)) HookOption {
+	return hookOption{f}
+}
@@ -0,0 +1,64 @@
+package lemon
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error of a hook that has panicked, with the value recovered from the panic and its stack trace.
+// It's the underlying error of a HookError with PhasePanic.
+type PanicError struct {
+	// Hook is the name of the hook.
+	Hook string
+	// Phase is the lifecycle step where the hook has panicked, such as PhaseStart, PhaseStop or PhaseCheck.
+	Phase Phase
+	// Value is the value recovered from the panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that has panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint(e.Value)
+}
+
+// Unwrap returns the recovered value if it's an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// recovered returns a HookError for given value, recovered from a panic of given hook and phase.
+// It must be called by the deferred function that has recovered the panic, in order to capture its stack trace.
+func recovered(name string, phase Phase, value interface{}) error {
+	return wrapError(name, PhasePanic, &PanicError{
+		Hook:  name,
+		Phase: phase,
+		Value: value,
+		Stack: debug.Stack(),
+	})
+}
+
+// Repanic will panic again, once every hook has shutdown gracefully, if a hook has panicked during its Start(), its
+// Stop() or any other callback such as a health check, instead of returning the panic as an error. The value of the
+// new panic is the PanicError of the first hook that has panicked: its stack trace is the one of the hook.
+//
+// It's useful to obtain a crash dump of the process, with GOTRACEBACK for example.
+func Repanic() Option {
+	return wrapOption(func(e *Engine) error {
+		e.repanic = true
+		return nil
+	})
+}
+
+// panicked returns the PanicError of the first hook that has panicked, if any.
+func (r *Report) panicked() *PanicError {
+	for _, err := range r.Errors() {
+		failure := &PanicError{}
+		if errors.As(err, &failure) {
+			return failure
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanic(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Start":   PanicStart,
+		"Stop":    PanicStop,
+		"Unwrap":  PanicUnwrap,
+		"Repanic": PanicRepanic,
+		"Check":   PanicCheck,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// panicHook panics with given value on Start().
+type panicHook struct {
+	value interface{}
+}
+
+func (p *panicHook) Start(ctx context.Context) error {
+	panic(p.value)
+}
+
+func (p *panicHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+// panicCheckHook panics on its health checks.
+type panicCheckHook struct {
+	checkHook
+}
+
+func (p *panicCheckHook) Check(ctx context.Context) error {
+	panic("Health check has crashed: 0xDEADC0DE")
+}
+
+// IsPanicError returns the PanicError of given error, which must have been raised by given hook and phase.
+func (r *TestRuntime) IsPanicError(err error, name string, phase Phase) *PanicError {
+
+	failure := &HookError{}
+	if !errors.As(err, &failure) || failure.Name != name || failure.Phase != PhasePanic {
+		r.Error("Unexpected error: %v", err)
+	}
+
+	panicked := &PanicError{}
+	if !errors.As(err, &panicked) {
+		r.Error("A PanicError was expected: %v", err)
+	}
+	if panicked.Hook != name || panicked.Phase != phase {
+		r.Error("Unexpected hook and phase: %s, %s", panicked.Hook, panicked.Phase)
+	}
+	if !strings.Contains(string(panicked.Stack), "lemon.(*testHook)") {
+		r.Error("Stack trace should contain the hook:\n%s", panicked.Stack)
+	}
+
+	return panicked
+}
+
+func PanicStart(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{panicOnStart: true}, Name("database"))
+
+	err = engine.Start()
+	failure := runtime.IsPanicError(err, "database", PhaseStart)
+
+	if failure.Value != "Hook has crashed: 0xDEADC0DE" {
+		runtime.Error("Unexpected value: %v", failure.Value)
+	}
+	if err.Error() != "lemon hook database has panicked: Hook has crashed: 0xDEADC0DE" {
+		runtime.Error("Unexpected error: %s", err)
+	}
+
+	runtime.Log("Hook has panicked on start.")
+
+}
+
+func PanicStop(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1), panicOnStop: true}, Name("database"))
+
+	result := make(chan *Report, 1)
+	go func() {
+		result <- engine.Run()
+	}()
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	// Since the hook has panicked on stop, its Start() never returns.
+	report := <-result
+	if len(report.Hooks) != 1 || len(report.Hooks[0].Errors) != 2 || !report.Hooks[0].TimedOut {
+		runtime.Error("Unexpected report: %+v", report)
+	}
+
+	runtime.IsPanicError(report.Hooks[0].Errors[0], "database", PhaseStop)
+
+	runtime.Log("Hook has panicked on stop.")
+
+}
+
+func PanicUnwrap(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	value := errors.New("an error has occurred: foobar")
+	engine.Register(&panicHook{value: value}, Name("database"))
+
+	err = engine.Start()
+	if !errors.Is(err, value) {
+		runtime.Error("Recovered error should have been unwrapped: %v", err)
+	}
+
+	failure := &PanicError{}
+	if !errors.As(err, &failure) || failure.Value != value {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Hook has panicked with an error.")
+
+}
+
+func PanicRepanic(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Repanic())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook, Name("database"))
+	engine.Register(&testHook{panicOnStart: true}, Name("cache"), DependsOn(hook))
+
+	value := func() (value interface{}) {
+		defer func() {
+			value = recover()
+		}()
+		engine.Start()
+		return nil
+	}()
+
+	failure, ok := value.(*PanicError)
+	if !ok {
+		runtime.Error("Engine should have panicked with a PanicError: %v", value)
+	}
+	if failure.Hook != "cache" || failure.Phase != PhaseStart {
+		runtime.Error("Unexpected hook and phase: %s, %s", failure.Hook, failure.Phase)
+	}
+	if !strings.Contains(string(failure.Stack), "lemon.(*testHook).Start") {
+		runtime.Error("Stack trace should contain the hook:\n%s", failure.Stack)
+	}
+
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if !hook.stopDone || !hook.startDone {
+		runtime.Error("Hook should have been gracefully shutdown")
+	}
+
+	runtime.Log("Engine has panicked again once every hook has shutdown.")
+
+}
+
+func PanicCheck(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&panicCheckHook{}, Name("database"), HealthCheck(CheckPolicy{
+		Interval:  10 * time.Millisecond,
+		Threshold: 1,
+		Action:    CheckShutdown,
+	}))
+
+	err = engine.Start()
+
+	failure := &HookError{}
+	if !errors.As(err, &failure) || failure.Name != "database" || failure.Phase != PhasePanic {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	panicked := &PanicError{}
+	if !errors.As(err, &panicked) || panicked.Phase != PhaseCheck {
+		runtime.Error("A PanicError was expected: %v", err)
+	}
+	if !strings.Contains(string(panicked.Stack), "lemon.(*panicCheckHook).Check") {
+		runtime.Error("Stack trace should contain the hook:\n%s", panicked.Stack)
+	}
+
+	metrics := runtime.Scrape(engine)
+	if runtime.HasMetric(metrics, `lemon_hook_panics_total{hook="database"}`) != 1 {
+		runtime.Error("Hook should have panicked once")
+	}
+
+	runtime.Log("Hook has panicked on its health check.")
+
+}
@@ -0,0 +1,161 @@
+package lemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrAlreadyRunning is returned when the pid file is locked by another instance.
+	ErrAlreadyRunning = errors.New("invalid pid file: another instance is running")
+)
+
+// openFile opens the pid file.
+var openFile = os.OpenFile
+
+// PIDFile sets the path of a pid file, which is written before any hook starts and removed once the engine has
+// shutdown.
+//
+// The pid file is locked for the whole lifecycle of the engine: if another instance holds the lock, the engine
+// fails to start with ErrAlreadyRunning. A pid file that isn't locked has been left by a dead process: it's stale
+// and it's replaced. On upgrade, the lock is shared with the new process, which takes over the pid file once it's
+// ready: if the upgrade fails, the pid file still belongs to this process.
+// Locking is only supported on Unix systems.
+func PIDFile(path string) Option {
+	return wrapOption(func(e *Engine) error {
+		e.pidPath = path
+		return nil
+	})
+}
+
+// acquirePIDFile locks the pid file, if defined, and writes the pid of the process.
+// It must be called with the engine's mutex.
+func (e *Engine) acquirePIDFile() error {
+
+	if e.pidPath == "" {
+		return nil
+	}
+
+	// A process started by Upgrade() has inherited the pid file from its parent.
+	file := e.pidFile
+	if file == nil {
+		var err error
+		file, err = e.lockPIDFile()
+		if err != nil {
+			return err
+		}
+	} else if lockFile(file) != nil {
+		return e.lockedPIDFile(file)
+	}
+
+	e.pidFile = file
+
+	// The parent keeps running until this process is ready, so the pid file still belongs to the parent: it's
+	// written once this process reports its readiness.
+	if e.handoff != nil {
+		return nil
+	}
+
+	err := e.writePIDFile()
+	if err != nil {
+		_ = file.Close()
+		e.pidFile = nil
+		return err
+	}
+
+	return nil
+}
+
+// writePIDFile replaces the content of the pid file, if defined, with the pid of the process.
+// It must be called with the engine's mutex.
+func (e *Engine) writePIDFile() error {
+
+	if e.pidPath == "" || e.pidFile == nil {
+		return nil
+	}
+
+	// Since the lock is held, any previous content is stale.
+	err := e.pidFile.Truncate(0)
+	if err == nil {
+		_, err = e.pidFile.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return err
+}
+
+// restorePIDFile writes the pid of the process again in the pid file, if any, after a failed upgrade.
+func (e *Engine) restorePIDFile() {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	_ = e.writePIDFile()
+}
+
+// lockPIDFile opens and locks the pid file.
+// Since an instance removes the pid file before releasing its lock, the locked file could have been removed, and
+// replaced by another instance, in the meantime: then, the pid file is opened again.
+func (e *Engine) lockPIDFile() (*os.File, error) {
+	for {
+
+		file, err := openFile(e.pidPath, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		if lockFile(file) != nil {
+			return nil, e.lockedPIDFile(file)
+		}
+
+		if e.isPIDFile(file) {
+			return file, nil
+		}
+
+		_ = file.Close()
+	}
+}
+
+// isPIDFile returns if given file is still the one at the path of the pid file.
+func (e *Engine) isPIDFile(file *os.File) bool {
+
+	locked, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	current, err := os.Stat(e.pidPath)
+	return err == nil && os.SameFile(locked, current)
+}
+
+// lockedPIDFile closes given pid file, locked by another instance, and returns an error with the pid of this
+// instance.
+func (e *Engine) lockedPIDFile(file *os.File) error {
+
+	content := make([]byte, 32)
+	n, _ := file.ReadAt(content, 0)
+	_ = file.Close()
+
+	pid := strings.TrimSpace(string(content[:n]))
+	return fmt.Errorf("%w: %s is locked by pid %s", ErrAlreadyRunning, e.pidPath, pid)
+}
+
+// releasePIDFile removes the pid file, if defined, and releases its lock.
+// If the process has been upgraded, the pid file belongs to the new process: it's kept. Likewise, if the process
+// has been started by Upgrade() but it has never been ready, the pid file still belongs to its parent.
+// It must be called with the engine's mutex.
+func (e *Engine) releasePIDFile() {
+
+	if e.pidFile == nil {
+		return
+	}
+
+	if !e.upgraded && e.handoff == nil {
+		_ = os.Remove(e.pidPath)
+	}
+
+	_ = e.pidFile.Close()
+	e.pidFile = nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package lemon
+
+import (
+	"os"
+)
+
+// lockFile is a no-op, since locking isn't supported on this system.
+func lockFile(file *os.File) error {
+	return nil
+}
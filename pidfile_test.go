@@ -0,0 +1,419 @@
+package lemon
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPIDFile(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle": PIDFileLifecycle,
+		"Locked":    PIDFileLocked,
+		"Stale":     PIDFileStale,
+		"Upgrade":   PIDFileUpgrade,
+		"Exited":    PIDFileUpgradeExited,
+		"Timeout":   PIDFileUpgradeTimeout,
+		"Race":      PIDFileRace,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// PIDFilePath returns the path of a pid file in a temporary directory, with a function to remove it.
+func (r *TestRuntime) PIDFilePath() (string, func()) {
+
+	directory, err := os.MkdirTemp("", "lemon")
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	return filepath.Join(directory, "lemon.pid"), func() {
+		_ = os.RemoveAll(directory)
+	}
+}
+
+// ReadPID returns the pid written in given pid file.
+func (r *TestRuntime) ReadPID(path string) int {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		r.Error("Unexpected pid file content: %q", content)
+	}
+
+	return pid
+}
+
+func (r *TestRuntime) HasNoPIDFile(path string) {
+	_, err := os.Stat(path)
+	if !os.IsNotExist(err) {
+		r.Error("Pid file should have been removed: %v", err)
+	}
+}
+
+func PIDFileLifecycle(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	engine, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if runtime.ReadPID(path) != os.Getpid() {
+		runtime.Error("Pid file should contain pid %d", os.Getpid())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasNoPIDFile(path)
+
+	runtime.Log("Engine has written and removed its pid file.")
+
+}
+
+func PIDFileLocked(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	engine1, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine2, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine1.Register(&testHook{kill: make(chan struct{}, 1)})
+	engine2.Register(hook)
+
+	result := runtime.StartEngine(engine1)
+
+	err = engine1.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine2.Start()
+	if !errors.Is(err, ErrAlreadyRunning) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+	if engine2.State() != StateIdle {
+		runtime.Error("Engine shouldn't have started: %s", engine2.State())
+	}
+
+	hook.mutex.Lock()
+	if hook.startCalled {
+		runtime.Error("Hook shouldn't have started")
+	}
+	hook.mutex.Unlock()
+
+	if runtime.ReadPID(path) != os.Getpid() {
+		runtime.Error("Pid file shouldn't have been modified")
+	}
+
+	engine1.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has failed to start: %s", err)
+
+}
+
+func PIDFileStale(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	// A pid file left by a dead process, without any lock.
+	err := os.WriteFile(path, []byte("4194304\n"), 0o644)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if runtime.ReadPID(path) != os.Getpid() {
+		runtime.Error("Stale pid file should have been replaced")
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasNoPIDFile(path)
+
+	runtime.Log("Engine has replaced a stale pid file.")
+
+}
+
+func PIDFileUpgrade(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	reexec := runtime.Reexec("serve", path)
+	defer reexec()
+
+	engine, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	address := <-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	pid := runtime.ReadPID(path)
+	if pid == os.Getpid() {
+		runtime.Error("Pid file should belong to the new process")
+	}
+
+	// Release the new process, which removes the pid file once it has shutdown.
+	conn, err := net.DialTimeout("tcp", address.String(), time.Second)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _ = bufio.NewReader(conn).ReadString('\n')
+	_ = conn.Close()
+
+	for i := 0; i < 100; i++ {
+		_, err = os.Stat(path)
+		if os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	runtime.HasNoPIDFile(path)
+
+	runtime.Log("Engine has handed off its pid file to process %d.", pid)
+
+}
+
+func PIDFileUpgradeExited(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	reexec := runtime.Reexec("fail", path)
+	defer reexec()
+
+	engine1, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine2, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine1.Register(hook)
+	engine2.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine1)
+	<-hook.address
+
+	err = engine1.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine1.Upgrade(runtime.Context())
+	if !errors.Is(err, ErrUpgradeExited) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	// The new process has exited without removing the pid file, which is still locked.
+	if runtime.ReadPID(path) != os.Getpid() {
+		runtime.Error("Pid file should still belong to the engine")
+	}
+
+	err = engine2.Start()
+	if !errors.Is(err, ErrAlreadyRunning) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	engine1.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasNoPIDFile(path)
+
+	runtime.Log("Engine has kept its pid file after a new process has exited.")
+
+}
+
+func PIDFileUpgradeTimeout(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	reexec := runtime.Reexec("stuck", path)
+	defer reexec()
+
+	engine, err := New(runtime.Context(), DisableSignal(), PIDFile(path), UpgradeTimeout(500*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	<-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if !errors.Is(err, ErrUpgradeTimeout) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	// The new process has been killed without taking over the pid file.
+	if runtime.ReadPID(path) != os.Getpid() {
+		runtime.Error("Pid file should still belong to the engine")
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasNoPIDFile(path)
+
+	runtime.Log("Engine has kept its pid file after a new process has timed out.")
+
+}
+
+func PIDFileRace(runtime *TestRuntime) {
+
+	path, cleanup := runtime.PIDFilePath()
+	defer cleanup()
+
+	// A pid file left by a previous instance, which is shutting down.
+	err := os.WriteFile(path, []byte("4194304\n"), 0o644)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	// Once opened, the pid file is removed by the previous instance, and created again by a third instance.
+	var other *os.File
+	previous := openFile
+	openFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		file, err := previous(name, flag, perm)
+		if other == nil {
+			_ = os.Remove(path)
+			other, err = previous(name, flag, perm)
+			if err != nil {
+				runtime.Error("An error wasn't expected: %s", err)
+			}
+			err = lockFile(other)
+			if err != nil {
+				runtime.Error("An error wasn't expected: %s", err)
+			}
+			_, err = other.WriteString("4194305\n")
+		}
+		return file, err
+	}
+	defer func() {
+		openFile = previous
+		_ = other.Close()
+	}()
+
+	engine, err := New(runtime.Context(), DisableSignal(), PIDFile(path))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	err = engine.Start()
+	if !errors.Is(err, ErrAlreadyRunning) || !strings.HasSuffix(err.Error(), "locked by pid 4194305") {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine has failed to start with a replaced pid file: %s", err)
+
+}
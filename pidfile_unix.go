@@ -0,0 +1,13 @@
+//go:build unix
+
+package lemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on given file, without blocking.
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
@@ -0,0 +1,144 @@
+package lemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// readyKey is the context key of the readiness notifier given to a Hook.
+type readyKey struct{}
+
+// Ready reports that the hook owning given context is ready.
+// It must be called with the context given to Start(), by a hook registered with ReportsReady().
+// Calling Ready more than once, or with another context, has no effect.
+func Ready(ctx context.Context) {
+	notify, ok := ctx.Value(readyKey{}).(func())
+	if ok && notify != nil {
+		notify()
+	}
+}
+
+// ReportsReady declares that the registered hook will report its readiness by calling Ready() with the context
+// given to Start(). Otherwise, a hook is considered ready as soon as its Start() is executed.
+func ReportsReady() HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+		h.reportsReady = true
+	})
+}
+
+// ReadyError is returned by WaitReady when hooks are still pending.
+type ReadyError struct {
+	// Pending contains hooks that haven't reported their readiness.
+	Pending []Hook
+	// Names contains the name of each pending hook, in the same order.
+	Names []string
+	// Err is the reason why the engine has stopped waiting for readiness.
+	Err error
+}
+
+func (e *ReadyError) Error() string {
+	return fmt.Sprintf("lemon is not ready: %d hook(s) pending (%s): %s",
+		len(e.Pending), strings.Join(e.Names, ", "), e.Err)
+}
+
+// Ready returns a channel that is closed once every registered hook is ready.
+func (e *Engine) Ready() <-chan struct{} {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.ready
+}
+
+// WaitReady will block until every registered hook is ready.
+// If given context is done, or if the engine has shutdown before, a ReadyError is returned with pending hooks.
+func (e *Engine) WaitReady(ctx context.Context) error {
+
+	e.mutex.Lock()
+	ready := e.ready
+	done := e.ctx.Done()
+	e.mutex.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return e.notReady(ctx.Err())
+	case <-done:
+		return e.notReady(context.Canceled)
+	}
+}
+
+// notReady returns a ReadyError with every pending hook, and given reason.
+func (e *Engine) notReady(err error) *ReadyError {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	failure := &ReadyError{Pending: []Hook{}, Names: []string{}, Err: err}
+	for _, h := range e.pendingHooks() {
+		failure.Pending = append(failure.Pending, h.hook)
+		failure.Names = append(failure.Names, h.name)
+	}
+
+	return failure
+}
+
+// Pending returns registered hooks that haven't reported their readiness yet.
+func (e *Engine) Pending() []Hook {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	pending := []Hook{}
+	for _, h := range e.pendingHooks() {
+		pending = append(pending, h.hook)
+	}
+
+	return pending
+}
+
+// pendingHooks returns registered hooks that haven't reported their readiness yet.
+// It must be called with the engine's mutex.
+func (e *Engine) pendingHooks() []*hookEntry {
+	pending := []*hookEntry{}
+	for _, h := range e.hooks {
+		if h.ready == nil || !h.isReady() {
+			pending = append(pending, h)
+		}
+	}
+	return pending
+}
+
+// waitReady will notify that the engine is ready once every given hook is ready.
+// If the engine has a startup timeout, it will shutdown the engine once it has expired instead.
+func (e *Engine) waitReady(hooks []*hookEntry) {
+
+	var deadline <-chan time.Time
+	if e.startupTimeout > 0 {
+		timer := time.NewTimer(e.startupTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for _, h := range hooks {
+		select {
+		case <-h.ready:
+		case <-deadline:
+			if e.late(hooks...) {
+				return
+			}
+		case <-e.ctx.Done():
+			return
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.transition(StateRunning, StateStarting)
+	e.startup(nil)
+	close(e.ready)
+	e.unclaimed()
+	e.notifyUpgrade()
+}
@@ -0,0 +1,230 @@
+package lemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Implicit":   ReadyImplicit,
+		"Explicit":   ReadyExplicit,
+		"Dependency": ReadyDependency,
+		"Shutdown":   ReadyShutdown,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+type readyHook struct {
+	delay   time.Duration
+	journal *testJournal
+	id      string
+}
+
+func (r *readyHook) Start(ctx context.Context) error {
+	if r.delay >= 0 {
+		time.Sleep(r.delay)
+		if r.journal != nil {
+			r.journal.Add("ready:" + r.id)
+		}
+		Ready(ctx)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (r *readyHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (r *TestRuntime) StartEngine(engine *Engine) chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- engine.Start()
+	}()
+	return result
+}
+
+func ReadyImplicit(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	err = engine.WaitReady(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if len(engine.Pending()) != 0 {
+		runtime.Error("Unexpected pending hooks: %v", engine.Pending())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine is ready once hooks are started.")
+
+}
+
+func ReadyExplicit(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &readyHook{delay: 200 * time.Millisecond}
+
+	engine.Register(hook1)
+	engine.Register(hook2, ReportsReady())
+
+	result := runtime.StartEngine(engine)
+
+	ctx1, cancel1 := context.WithTimeout(runtime.Context(), 50*time.Millisecond)
+	defer cancel1()
+
+	err = engine.WaitReady(ctx1)
+	if err == nil {
+		runtime.Error("An error was expected")
+	}
+
+	failure, ok := err.(*ReadyError)
+	if !ok {
+		runtime.Error("Unexpected error: %s", err)
+	}
+	if len(failure.Pending) != 1 || failure.Pending[0] != hook2 {
+		runtime.Error("Unexpected pending hooks: %v", failure.Pending)
+	}
+	if len(failure.Names) != 1 || failure.Names[0] != "*lemon.readyHook" {
+		runtime.Error("Unexpected pending hooks: %v", failure.Names)
+	}
+	if !strings.Contains(err.Error(), "1 hook(s) pending (*lemon.readyHook)") {
+		runtime.Error("Pending hooks should have been named: %s", err)
+	}
+	if failure.Err != context.DeadlineExceeded {
+		runtime.Error("Unexpected error: %s", failure.Err)
+	}
+
+	ctx2, cancel2 := context.WithTimeout(runtime.Context(), time.Second)
+	defer cancel2()
+
+	err = engine.WaitReady(ctx2)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	select {
+	case <-engine.Ready():
+	default:
+		runtime.Error("Engine should be ready")
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine is ready once hooks have reported their readiness.")
+
+}
+
+func ReadyDependency(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	journal := &testJournal{}
+	database := &readyHook{id: "database", delay: 100 * time.Millisecond, journal: journal}
+	server := &orderHook{id: "server", journal: journal}
+
+	engine.Register(server, DependsOn(database))
+	engine.Register(database, ReportsReady())
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasOrder(journal, "ready:database", "start:server")
+
+	runtime.Log("Engine has started hook once its dependency was ready.")
+
+}
+
+func ReadyShutdown(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &readyHook{delay: -1}
+	engine.Register(hook, ReportsReady())
+
+	result := runtime.StartEngine(engine)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		engine.Stop()
+	}()
+
+	err = engine.WaitReady(runtime.Context())
+	if err == nil {
+		runtime.Error("An error was expected")
+	}
+
+	failure, ok := err.(*ReadyError)
+	if !ok {
+		runtime.Error("Unexpected error: %s", err)
+	}
+	if len(failure.Pending) != 1 || failure.Pending[0] != hook {
+		runtime.Error("Unexpected pending hooks: %v", failure.Pending)
+	}
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has stopped waiting for readiness on shutdown.")
+
+}
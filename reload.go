@@ -0,0 +1,198 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var (
+	// DefaultReloadSignal is the default signal that triggers a reload.
+	DefaultReloadSignal os.Signal = syscall.SIGHUP
+)
+
+var (
+	// ErrNotRunning is returned when a reload, or an upgrade, is required while the engine isn't running.
+	ErrNotRunning = errors.New("invalid state: engine is not running")
+)
+
+// Reloader is an optional interface for a Hook which can reload its configuration without being restarted.
+type Reloader interface {
+	// Reload is executed by the engine when a reload is required, either with Reload() or the reload signal.
+	// It's executed while the hook is running, with the context given to the engine's Reload().
+	Reload(context.Context) error
+}
+
+// Rollbacker is an optional interface for a Reloader which can revert its last successful reload.
+// It's used by the ReloadRollback policy.
+type Rollbacker interface {
+	// Rollback is executed by the engine when another hook has failed to reload.
+	Rollback(context.Context) error
+}
+
+// ReloadPolicy defines what the engine does when a hook has failed to reload.
+type ReloadPolicy int
+
+const (
+	// ReloadContinue forwards the error to the Logger, and the remaining hooks are reloaded anyway.
+	ReloadContinue ReloadPolicy = iota
+	// ReloadRollback stops the reload, and hooks that have already been reloaded are rolled back, in the reverse
+	// order, if they implement Rollbacker.
+	ReloadRollback
+	// ReloadFatal stops the reload, and shutdowns the engine with a HookError as cause.
+	ReloadFatal
+)
+
+// ReloadResult is the outcome of a reload for a hook.
+type ReloadResult struct {
+	// Name is the name of the hook.
+	Name string
+	// Err is the error returned by the hook's Reload(), if any.
+	Err error
+	// RolledBack defines if the hook has been rolled back, because another hook has failed to reload.
+	RolledBack bool
+}
+
+// ReloadSignal sets the signal that triggers a reload, instead of SIGHUP.
+// If given signal is nil, a reload could only be triggered with the engine's Reload().
+func ReloadSignal(signal os.Signal) Option {
+	return wrapOption(func(e *Engine) error {
+		e.reloadSignal = signal
+		e.noReload = signal == nil
+		return nil
+	})
+}
+
+// OnReloadFailure sets the policy used when a hook has failed to reload.
+// By default, the engine uses ReloadContinue.
+func OnReloadFailure(policy ReloadPolicy) Option {
+	return wrapOption(func(e *Engine) error {
+		e.reloadPolicy = policy
+		return nil
+	})
+}
+
+// Reload will execute the Reload() of every running hook that implements Reloader, in their registration order.
+// The process keeps running during the reload, and hooks are never restarted.
+//
+// It returns the result of every reloaded hook, with the first error that has occurred, if any: what happens then
+// depends on the engine's ReloadPolicy. If the engine isn't running, ErrNotRunning is returned.
+func (e *Engine) Reload(ctx context.Context) ([]ReloadResult, error) {
+	return e.reloadHooks(ctx, nil)
+}
+
+// reloadHooks will reload hooks, after given signal if the reload has been triggered by a signal.
+func (e *Engine) reloadHooks(ctx context.Context, sig os.Signal) ([]ReloadResult, error) {
+
+	// Only one reload is executed at a time.
+	e.reloading.Lock()
+	defer e.reloading.Unlock()
+
+	e.mutex.Lock()
+
+	if e.state != StateRunning {
+		e.mutex.Unlock()
+		return nil, ErrNotRunning
+	}
+
+	hooks := e.reloadable()
+
+	e.mutex.Unlock()
+
+	e.publish(Event{Type: EventReloadRequested, Signal: sig})
+
+	var cause error
+	results := make([]ReloadResult, 0, len(hooks))
+
+	for _, h := range hooks {
+
+		reloader := h.hook.(Reloader)
+
+		err := invoke(ctx, h.name, PhaseReload, reloader.Reload)
+		results = append(results, ReloadResult{Name: h.name, Err: err})
+
+		if err == nil {
+			e.publish(Event{Type: EventHookReloaded, Hook: h.name})
+			continue
+		}
+
+		e.failure(h, err)
+		e.publish(Event{Type: EventHookFailed, Hook: h.name, Err: err})
+
+		if cause == nil {
+			cause = err
+		}
+		if e.reloadPolicy != ReloadContinue {
+			break
+		}
+	}
+
+	if cause == nil {
+		return results, nil
+	}
+
+	switch e.reloadPolicy {
+	case ReloadRollback:
+		e.rollback(ctx, hooks, results)
+	case ReloadFatal:
+		e.abort(cause)
+	}
+
+	return results, cause
+}
+
+// reloadable returns every running hook that implements Reloader.
+// It must be called with the engine's mutex.
+func (e *Engine) reloadable() []*hookEntry {
+	hooks := []*hookEntry{}
+	for _, h := range e.hooks {
+		_, ok := h.hook.(Reloader)
+		if ok && (h.state == StateRunning || h.state == StateUnhealthy) {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// rollback will revert, in the reverse order, every hook that has been successfully reloaded.
+func (e *Engine) rollback(ctx context.Context, hooks []*hookEntry, results []ReloadResult) {
+	for i := len(results) - 1; i >= 0; i-- {
+
+		h := hooks[i]
+
+		rollbacker, ok := h.hook.(Rollbacker)
+		if !ok || results[i].Err != nil {
+			continue
+		}
+
+		err := invoke(ctx, h.name, PhaseRollback, rollbacker.Rollback)
+		if err != nil {
+			e.failure(h, err)
+			e.publish(Event{Type: EventHookFailed, Hook: h.name, Err: err})
+			continue
+		}
+
+		results[i].RolledBack = true
+	}
+}
+
+// waitReloadNotification will reload hooks whenever the reload signal is received, until the engine shuts down.
+func (e *Engine) waitReloadNotification() {
+
+	if e.reloadSignal != nil && !e.noReload {
+		signal.Notify(e.reloads, e.reloadSignal)
+		defer signal.Stop(e.reloads)
+	}
+
+	for {
+		select {
+		case sig := <-e.reloads:
+			// Errors are already forwarded to the Logger.
+			_, _ = e.reloadHooks(e.ctx, sig)
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
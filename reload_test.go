@@ -0,0 +1,278 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Signal":     ReloadWithSignal,
+		"Continue":   ReloadWithContinuePolicy,
+		"Rollback":   ReloadWithRollbackPolicy,
+		"Fatal":      ReloadWithFatalPolicy,
+		"NotRunning": ReloadNotRunning,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+var errReload = errors.New("cannot reload configuration: foobar")
+
+type reloadHook struct {
+	err       error
+	reloads   int64
+	rollbacks int64
+}
+
+func (r *reloadHook) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (r *reloadHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (r *reloadHook) Reload(ctx context.Context) error {
+	atomic.AddInt64(&r.reloads, 1)
+	return r.err
+}
+
+func (r *reloadHook) Rollback(ctx context.Context) error {
+	atomic.AddInt64(&r.rollbacks, 1)
+	return nil
+}
+
+func (r *TestRuntime) HasReloads(h *reloadHook, id string, reloads, rollbacks int64) {
+	if atomic.LoadInt64(&h.reloads) != reloads {
+		r.Error("Hook %s should have been reloaded %d times: %d", id, reloads, atomic.LoadInt64(&h.reloads))
+	}
+	if atomic.LoadInt64(&h.rollbacks) != rollbacks {
+		r.Error("Hook %s should have been rolled back %d times: %d", id, rollbacks, atomic.LoadInt64(&h.rollbacks))
+	}
+}
+
+func ReloadWithSignal(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+	if engine.reloadSignal != syscall.SIGHUP {
+		runtime.Error("Engine should reload on SIGHUP signal")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	hook := &reloadHook{}
+	engine.Register(hook, Name("hook"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.reloads <- syscall.SIGHUP
+
+	timeout := time.After(time.Second)
+	for reloaded := false; !reloaded; {
+		select {
+		case event := <-events:
+			if event.Type == EventReloadRequested && event.Signal != syscall.SIGHUP {
+				runtime.Error("Unexpected reload signal: %v", event.Signal)
+			}
+			reloaded = event.Type == EventHookReloaded && event.Hook == "hook"
+		case <-timeout:
+			runtime.Error("Hook should have been reloaded")
+		}
+	}
+
+	runtime.HasReloads(hook, "hook", 1, 0)
+	runtime.HasHookStates(engine, StateRunning)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has reloaded hooks on signal.")
+
+}
+
+func ReloadWithContinuePolicy(runtime *TestRuntime) {
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &reloadHook{err: errReload}
+	hook2 := &reloadHook{}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"))
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	results, err := engine.Reload(runtime.Context())
+	runtime.IsHookError(err, "hook1", PhaseReload, errReload)
+
+	if len(results) != 2 || results[0].Err != err || results[1].Err != nil {
+		runtime.Error("Unexpected reload results: %+v", results)
+	}
+
+	runtime.HasReloads(hook1, "hook1", 1, 0)
+	runtime.HasReloads(hook2, "hook2", 1, 0)
+
+	if engine.State() != StateRunning {
+		runtime.Error("Engine should still be running: %s", engine.State())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) != 1 {
+		runtime.Error("A failure was expected: %+v", failures)
+	}
+	runtime.IsHookError(failures[0], "hook1", PhaseReload, errReload)
+
+	runtime.Log("Engine has reloaded every hook despite a failure.")
+
+}
+
+func ReloadWithRollbackPolicy(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), OnReloadFailure(ReloadRollback))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &reloadHook{}
+	hook2 := &reloadHook{err: errReload}
+	hook3 := &reloadHook{}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"))
+	engine.Register(hook3, Name("hook3"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	results, err := engine.Reload(runtime.Context())
+	runtime.IsHookError(err, "hook2", PhaseReload, errReload)
+
+	if len(results) != 2 || !results[0].RolledBack || results[1].RolledBack {
+		runtime.Error("Unexpected reload results: %+v", results)
+	}
+
+	runtime.HasReloads(hook1, "hook1", 1, 1)
+	runtime.HasReloads(hook2, "hook2", 1, 0)
+	runtime.HasReloads(hook3, "hook3", 0, 0)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has rolled back reloaded hooks.")
+
+}
+
+func ReloadWithFatalPolicy(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), OnReloadFailure(ReloadFatal))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &reloadHook{err: errReload}
+	engine.Register(hook, Name("hook"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	_, err = engine.Reload(runtime.Context())
+	runtime.IsHookError(err, "hook", PhaseReload, errReload)
+
+	err = <-result
+	runtime.IsHookError(err, "hook", PhaseReload, errReload)
+
+	runtime.Log("Engine has shutdown because of a failed reload.")
+
+}
+
+func ReloadNotRunning(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), ReloadSignal(nil))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.init()
+	if engine.reloadSignal != nil {
+		runtime.Error("Engine should not reload on signal")
+	}
+
+	hook := &reloadHook{}
+	engine.Register(hook)
+
+	_, err = engine.Reload(runtime.Context())
+	if err != ErrNotRunning {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.HasReloads(hook, "hook", 0, 0)
+
+	runtime.Log("Engine cannot reload hooks before it's running.")
+
+}
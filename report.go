@@ -0,0 +1,184 @@
+package lemon
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Report summarises the lifecycle of an engine, from its startup to its shutdown.
+//
+// A Report can be used as an error which joins every error that has occurred: use Err to obtain it.
+type Report struct {
+	// Cause is the first error that has triggered the shutdown, if any.
+	Cause error
+	// Hooks contains the report of every registered hook, in their registration order.
+	Hooks []HookReport
+}
+
+// HookReport summarises the lifecycle of a hook.
+type HookReport struct {
+	// Name is the name of the hook.
+	Name string
+	// Errors contains every error that has occurred during startup and shutdown of the hook.
+	Errors []error
+	// TimedOut defines if the hook has not shutdown before its timeout.
+	TimedOut bool
+	// Started is when the hook has started. It's undefined if the hook has never started.
+	Started time.Time
+	// Uptime is the amount of time the hook has run before being asked to shutdown.
+	Uptime time.Duration
+	// Shutdown is the amount of time the hook has taken to shutdown.
+	Shutdown time.Duration
+	// Leaked defines if Start() or Stop() of the hook are still running once the engine has shutdown, after the
+	// hook's timeout.
+	Leaked bool
+	// Dump contains the stack trace of each goroutine of the hook that is still running, if the hook is leaked and
+	// DumpLeaks is defined.
+	Dump []byte
+}
+
+// TimedOut returns the name of every hook that has not shutdown before its timeout.
+func (r *Report) TimedOut() []string {
+	names := []string{}
+	for _, h := range r.Hooks {
+		if h.TimedOut {
+			names = append(names, h.Name)
+		}
+	}
+	return names
+}
+
+// Errors returns every error that has occurred, starting with the cause.
+// Each error is returned once, even if the cause is, or joins, an error of a hook.
+func (r *Report) Errors() []error {
+
+	failures := []error{}
+	if r.Cause != nil {
+		failures = append(failures, r.Cause)
+	}
+
+	for _, h := range r.Hooks {
+		for _, err := range h.Errors {
+			if !r.caused(err) {
+				failures = append(failures, err)
+			}
+		}
+	}
+
+	return failures
+}
+
+// caused returns if given error is the cause, or one of the errors joined by the cause.
+func (r *Report) caused(err error) bool {
+
+	if err == r.Cause {
+		return true
+	}
+
+	if joined, ok := r.Cause.(interface{ Unwrap() []error }); ok {
+		for _, cause := range joined.Unwrap() {
+			if err == cause {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Err returns the report as an error if at least one error has occurred, or nil otherwise.
+func (r *Report) Err() error {
+	if len(r.Errors()) == 0 {
+		return nil
+	}
+	return r
+}
+
+// Error joins the message of every error that has occurred, separated by a newline.
+func (r *Report) Error() string {
+	messages := []string{}
+	for _, err := range r.Errors() {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns every error that has occurred, so a report is compatible with errors.Is and errors.As.
+func (r *Report) Unwrap() []error {
+	return r.Errors()
+}
+
+// report creates the report of the engine's lifecycle.
+// It must be called with the engine's mutex.
+func (e *Engine) report() *Report {
+
+	report := &Report{
+		Cause: e.cause,
+		Hooks: make([]HookReport, 0, len(e.hooks)),
+	}
+
+	for _, h := range e.hooks {
+
+		hook := HookReport{
+			Name:    h.name,
+			Errors:  append([]error{}, h.failures...),
+			Started: h.started,
+		}
+
+		for _, err := range h.failures {
+			if errors.Is(err, ErrShutdownTimeout) {
+				hook.TimedOut = true
+			}
+		}
+
+		if !h.started.IsZero() && !h.stopping.IsZero() {
+			hook.Uptime = h.stopping.Sub(h.started)
+		}
+
+		if !h.stopping.IsZero() && !h.stopped.IsZero() {
+			hook.Shutdown = h.stopped.Sub(h.stopping)
+		}
+
+		hook.Leaked = h.tracker.leaked()
+		if hook.Leaked && e.dumpLeaks {
+			hook.Dump = h.tracker.dump()
+		}
+
+		report.Hooks = append(report.Hooks, hook)
+	}
+
+	return report
+}
+
+// failure records an error that occurs during given hook lifecycle, and forwards it to the logger.
+func (e *Engine) failure(h *hookEntry, err error) {
+
+	if err == nil {
+		return
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.log(err)
+	h.failures = append(h.failures, err)
+}
+
+// abort will shutdown the engine with given error as cause, unless a previous error has already triggered a shutdown.
+func (e *Engine) abort(err error) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.ctx.Err() == nil {
+		e.transition(StateStopping, StateStarting, StateRunning)
+		e.traceShutdown(err)
+		e.publish(Event{Type: EventShutdownRequested, Trigger: TriggerFailure, Err: err})
+	}
+
+	e.cancel()
+	if e.cause == nil {
+		e.cause = err
+	}
+}
@@ -0,0 +1,161 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Success":  ReportSuccess,
+		"Failures": ReportFailures,
+		"Timeout":  ReportTimeout,
+		"Invalid":  ReportInvalid,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+func ReportSuccess(runtime *TestRuntime) {
+
+	kill := 100 * time.Millisecond
+	epsilon := 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook1"))
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook2"))
+
+	report := engine.Run()
+	if report.Err() != nil {
+		runtime.Error("An error wasn't expected: %s", report.Err())
+	}
+
+	if len(report.Hooks) != 2 || report.Hooks[0].Name != "hook1" || report.Hooks[1].Name != "hook2" {
+		runtime.Error("Unexpected hooks: %+v", report.Hooks)
+	}
+
+	for _, hook := range report.Hooks {
+		if hook.Started.IsZero() {
+			runtime.Error("Hook %s should have a start time", hook.Name)
+		}
+		runtime.InEpsilon(hook.Uptime, kill, epsilon, "Hook has an unexpected uptime")
+		runtime.InDelta(hook.Shutdown, epsilon, "Hook has an unexpected shutdown duration")
+	}
+
+	runtime.Log("Engine has returned a report without error.")
+
+}
+
+func ReportFailures(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{startError: errors.New("an error has occurred: foo")}
+	hook2 := &testHook{
+		startError: errors.New("an error has occurred: bar"),
+		startDelay: 50 * time.Millisecond,
+	}
+	hook3 := &testHook{kill: make(chan struct{}, 1), stopError: errors.New("cannot stop service: foobar")}
+
+	engine.Register(hook1, Name("hook1"))
+	engine.Register(hook2, Name("hook2"))
+	engine.Register(hook3, Name("hook3"))
+
+	report := engine.Run()
+
+	runtime.IsHookError(report.Cause, "hook1", PhaseStart, hook1.startError)
+
+	err = report.Err()
+	if err == nil {
+		runtime.Error("An error was expected")
+	}
+
+	for _, expected := range []error{hook1.startError, hook2.startError, hook3.stopError} {
+		if !errors.Is(err, expected) {
+			runtime.Error("Report should contains error: %s", expected)
+		}
+	}
+
+	if len(report.Errors()) != 3 {
+		runtime.Error("Unexpected errors: %+v", report.Errors())
+	}
+
+	runtime.Log("Engine has returned a report with every errors:\n%s", err)
+
+}
+
+func ReportTimeout(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 20*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, Timeout(50*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook1"))
+	engine.Register(&testHook{kill: make(chan struct{}), stopTimeout: true}, Name("hook2"))
+
+	report := engine.Run()
+	if report.Cause != nil {
+		runtime.Error("An error wasn't expected: %s", report.Cause)
+	}
+
+	timeout := report.TimedOut()
+	if len(timeout) != 1 || timeout[0] != "hook2" {
+		runtime.Error("Unexpected hooks with timeout: %v", timeout)
+	}
+
+	if !errors.Is(report.Err(), ErrShutdownTimeout) {
+		runtime.Error("Unexpected error: %v", report.Err())
+	}
+
+	runtime.Log("Engine has returned a report with hooks that have timed out.")
+
+}
+
+func ReportInvalid(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook, DependsOn(&testHook{}))
+
+	report := engine.Run()
+	if !errors.Is(report.Err(), ErrMissingDependency) {
+		runtime.Error("Unexpected error: %v", report.Err())
+	}
+
+	runtime.Log("Engine has returned a report with an invalid configuration.")
+
+}
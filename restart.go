@@ -0,0 +1,172 @@
+package lemon
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// DefaultBackoff is the default amount of time the engine will wait before the first restart of a hook.
+	DefaultBackoff = 100 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum amount of time the engine will wait before restarting a hook.
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultBackoffMultiplier is the default factor applied on the backoff after each restart.
+	DefaultBackoffMultiplier = 2.0
+)
+
+// RestartMode defines when a hook should be restarted.
+type RestartMode int
+
+const (
+	// RestartNever will never restart a hook: if its Start() returns an error, the engine will shutdown.
+	RestartNever RestartMode = iota
+	// RestartOnFailure will restart a hook if its Start() returns an error.
+	RestartOnFailure
+	// RestartAlways will restart a hook whenever its Start() returns, unless the engine is shutting down.
+	RestartAlways
+)
+
+// RestartPolicy defines how a hook is supervised by the engine.
+//
+// When a hook is restarted, the engine waits for an exponential backoff: it starts with Backoff, and it's multiplied
+// by Multiplier after each restart, up to MaxBackoff. Jitter is a ratio, between 0 and 1, of this backoff that is
+// randomly removed, so restarts of many hooks are spread over time.
+//
+// Once MaxRestarts is reached, the engine will shutdown if the hook has failed. A zero MaxRestarts means that
+// the hook is restarted indefinitely.
+type RestartPolicy struct {
+	Mode        RestartMode
+	MaxRestarts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Multiplier  float64
+	Jitter      float64
+}
+
+// Restart defines the policy used to supervise the registered hook.
+// Invalid or zero values of given policy are replaced by their default value.
+func Restart(policy RestartPolicy) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+
+		if policy.Backoff <= 0 {
+			policy.Backoff = DefaultBackoff
+		}
+
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = DefaultMaxBackoff
+		}
+
+		if policy.MaxBackoff < policy.Backoff {
+			policy.MaxBackoff = policy.Backoff
+		}
+
+		if policy.Multiplier < 1 {
+			policy.Multiplier = DefaultBackoffMultiplier
+		}
+
+		if policy.Jitter < 0 {
+			policy.Jitter = 0
+		}
+
+		if policy.Jitter > 1 {
+			policy.Jitter = 1
+		}
+
+		h.policy = policy
+
+	})
+}
+
+// retry returns if a hook should be restarted, given the error returned by its Start() and its number of restarts.
+func (p RestartPolicy) retry(err error, restarts int) bool {
+
+	if p.MaxRestarts > 0 && restarts >= p.MaxRestarts {
+		return false
+	}
+
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// backoff returns the amount of time to wait before the next restart of a hook.
+func (p RestartPolicy) backoff(restarts int) time.Duration {
+
+	delay := float64(p.Backoff) * math.Pow(p.Multiplier, float64(restarts))
+	if delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	// #nosec G404: jitter doesn't require a cryptographically secure random number.
+	delay -= delay * p.Jitter * rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// Supervise will block like WaitForEvent, but it restarts the given Hook according to given policy when its
+// Start() returns before a shutdown is required. Also, the Hook is restarted whenever Interrupt() is called.
+// Every error that has occurred before a restart is forwarded on given handler.
+// If the policy is exhausted, the last error returned by the Hook is returned, so the Engine will shutdown.
+func (hr *HookRuntime) Supervise(ctx context.Context, h Hook, policy RestartPolicy, handler func(error)) error {
+
+	defer hr.release()
+
+	for restarts := 0; ; restarts++ {
+
+		err := hr.WaitForEvent(hr.attempt(ctx), h)
+
+		if ctx.Err() == nil && hr.isInterrupted() {
+			// A restart has been required while the Hook was running: wait for its shutdown before restarting it.
+			// This restart isn't governed by the policy.
+			hr.restart(err, handler)
+			restarts--
+			continue
+		}
+
+		if ctx.Err() != nil || !policy.retry(err, restarts) {
+			return err
+		}
+
+		handler(err)
+
+		// Discard the result forwarded by WaitForEvent, since Start() will be executed again.
+		<-hr.c1
+
+		select {
+		case <-time.After(policy.backoff(restarts)):
+		case <-ctx.Done():
+			// Hook isn't running anymore: forward that c1 has stopped and ignore Hook shutdown.
+			hr.c1 <- nil
+			hr.w0 = false
+			return nil
+		}
+	}
+}
+
+// restart waits for the shutdown of the current Hook execution, once a restart has been required with Interrupt().
+// Every error that has occurred is forwarded on given handler.
+func (hr *HookRuntime) restart(err error, handler func(error)) {
+
+	if err != nil {
+		handler(err)
+	}
+
+	timeout := hr.timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	for _, failure := range hr.Shutdown(timeout) {
+		handler(failure)
+	}
+
+	hr.c0 = nil
+	hr.c1 = nil
+}
@@ -0,0 +1,228 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestart(t *testing.T) {
+	tests := map[string]TestHandler{
+		"OnFailure": RestartOnFailureRecover,
+		"Exhausted": RestartOnFailureExhausted,
+		"Always":    RestartAlwaysOnReturn,
+		"Shutdown":  RestartShutdownDuringBackoff,
+		"Backoff":   RestartBackoff,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+type flakyHook struct {
+	starts   int64
+	failures int64
+	err      error
+}
+
+func (f *flakyHook) Start(ctx context.Context) error {
+	n := atomic.AddInt64(&f.starts, 1)
+	if f.failures < 0 || n <= f.failures {
+		return f.err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (f *flakyHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (f *flakyHook) Starts() int64 {
+	return atomic.LoadInt64(&f.starts)
+}
+
+func RestartOnFailureRecover(runtime *TestRuntime) {
+
+	failures := int64(0)
+	handler := func(err error) {
+		atomic.AddInt64(&failures, 1)
+	}
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, Logger(handler))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &flakyHook{failures: 3, err: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Restart(RestartPolicy{
+		Mode:        RestartOnFailure,
+		MaxRestarts: 5,
+		Backoff:     10 * time.Millisecond,
+	}))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if hook.Starts() != 4 {
+		runtime.Error("Unexpected number of starts: %d", hook.Starts())
+	}
+
+	if atomic.LoadInt64(&failures) != 3 {
+		runtime.Error("Unexpected number of failures: %d", atomic.LoadInt64(&failures))
+	}
+
+	runtime.Log("Engine has restarted a failing hook.")
+
+}
+
+func RestartOnFailureExhausted(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &flakyHook{failures: -1, err: errors.New("an error has occurred: foobar")}
+
+	engine.Register(hook1)
+	engine.Register(hook2, Restart(RestartPolicy{
+		Mode:        RestartOnFailure,
+		MaxRestarts: 2,
+		Backoff:     10 * time.Millisecond,
+	}))
+
+	err = engine.Start()
+	if !errors.Is(err, hook2.err) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	if hook2.Starts() != 3 {
+		runtime.Error("Unexpected number of starts: %d", hook2.Starts())
+	}
+
+	runtime.HasLifecycle(hook1, "hook1")
+
+	runtime.Log("Engine has shutdown once restart policy was exhausted.")
+
+}
+
+func RestartAlwaysOnReturn(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &flakyHook{failures: 2}
+	engine.Register(hook, Restart(RestartPolicy{
+		Mode:    RestartAlways,
+		Backoff: 10 * time.Millisecond,
+	}))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if hook.Starts() != 3 {
+		runtime.Error("Unexpected number of starts: %d", hook.Starts())
+	}
+
+	runtime.Log("Engine has restarted a hook which has returned.")
+
+}
+
+func RestartShutdownDuringBackoff(runtime *TestRuntime) {
+
+	kill := 100 * time.Millisecond
+	maximum := kill + 50*time.Millisecond
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &flakyHook{failures: -1, err: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Restart(RestartPolicy{
+		Mode:    RestartOnFailure,
+		Backoff: time.Hour,
+	}))
+
+	now := time.Now()
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.InDelta(time.Since(now), maximum, "Engine took way too long to shutdown")
+
+	if hook.Starts() != 1 {
+		runtime.Error("Unexpected number of starts: %d", hook.Starts())
+	}
+
+	runtime.Log("Engine has shutdown while waiting to restart a hook.")
+
+}
+
+func RestartBackoff(runtime *TestRuntime) {
+
+	policy := RestartPolicy{
+		Backoff:    100 * time.Millisecond,
+		MaxBackoff: time.Second,
+		Multiplier: 2,
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+
+	for i := range expected {
+		if policy.backoff(i) != expected[i] {
+			runtime.Error("Unexpected backoff for restart %d: %s", i, policy.backoff(i))
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := range expected {
+		delay := policy.backoff(i)
+		if delay > expected[i] || delay < expected[i]/2 {
+			runtime.Error("Unexpected backoff with jitter for restart %d: %s", i, delay)
+		}
+	}
+
+	runtime.Log("Restart policy has an exponential backoff.")
+
+}
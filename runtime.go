@@ -2,7 +2,7 @@ package lemon
 
 import (
 	"context"
-	"fmt"
+	"sync"
 	"time"
 )
 
@@ -22,32 +22,118 @@ type HookRuntime struct {
 	w0 bool
 	// wait flag for c1.
 	w1 bool
+	// name of the Hook, used by errors.
+	name string
+	// observer receives lifecycle events of the Hook, if defined.
+	observer func(EventType, error)
+	// entered is executed by the goroutine of Start(), right before the Hook is started, if defined.
+	entered func()
+	// timeout is used to shutdown the Hook before a restart required with Interrupt().
+	timeout time.Duration
+	// forced is closed when the Engine stops waiting for the Hook to gracefully shutdown, if defined.
+	forced <-chan struct{}
+	// tracker records goroutines executing Start() and Stop(), if defined.
+	tracker *tracker
+	// mutex protects cancel and interrupted.
+	mutex sync.Mutex
+	// cancel terminates the context of the current Hook execution.
+	cancel context.CancelFunc
+	// interrupted defines if a restart of the current Hook execution has been required.
+	interrupted bool
+}
+
+// emit forwards an event of the Hook to its observer.
+func (hr *HookRuntime) emit(kind EventType, err error) {
+	if hr.observer != nil {
+		hr.observer(kind, err)
+	}
+}
+
+// execute runs given callback of the Hook, for given phase, and returns its error, or its panic as an error.
+// The calling goroutine is tracked until the callback returns.
+func (hr *HookRuntime) execute(phase Phase, callback func() error) (err error) {
+	defer hr.tracker.track()()
+	defer func() {
+		value := recover()
+		if value != nil {
+			err = recovered(hr.name, phase, value)
+		}
+	}()
+	return wrapError(hr.name, phase, callback())
 }
 
 func (hr *HookRuntime) start(ctx context.Context, h Hook) {
+	// Keep a reference on the chan, since it could be replaced if this goroutine outlives a restart.
+	c1 := hr.c1
 	go func() {
-		defer func() {
-			err := recover()
-			if err != nil {
-				hr.c1 <- fmt.Errorf("lemon startup failed: %s", err)
-			}
-		}()
-		hr.c1 <- h.Start(ctx)
+		if hr.entered != nil {
+			hr.entered()
+		}
+		c1 <- hr.execute(PhaseStart, func() error {
+			return h.Start(ctx)
+		})
 	}()
 }
 
 func (hr *HookRuntime) stop(ctx context.Context, h Hook) {
+	// Keep a reference on the chan, since it could be replaced if this goroutine outlives a restart.
+	c0 := hr.c0
 	go func() {
-		defer func() {
-			err := recover()
-			if err != nil {
-				hr.c0 <- fmt.Errorf("lemon shutdown failed: %s", err)
-			}
-		}()
-		hr.c0 <- h.Stop(ctx)
+		c0 <- hr.execute(PhaseStop, func() error {
+			return h.Stop(ctx)
+		})
 	}()
 }
 
+// attempt returns the context of a new Hook execution, which can be cancelled with Interrupt().
+func (hr *HookRuntime) attempt(ctx context.Context) context.Context {
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+
+	if hr.cancel != nil {
+		hr.cancel()
+	}
+
+	ctx, hr.cancel = context.WithCancel(ctx)
+	hr.interrupted = false
+
+	return ctx
+}
+
+// isInterrupted returns if a restart of the current Hook execution has been required.
+func (hr *HookRuntime) isInterrupted() bool {
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+
+	return hr.interrupted
+}
+
+// release terminates the context of the last Hook execution.
+func (hr *HookRuntime) release() {
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+
+	if hr.cancel != nil {
+		hr.cancel()
+	}
+}
+
+// Interrupt requires a restart of the Hook supervised with Supervise(): its current execution is shutdown, within
+// the runtime's timeout, and then the Hook is started again.
+func (hr *HookRuntime) Interrupt() {
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+
+	if hr.cancel != nil {
+		hr.interrupted = true
+		hr.cancel()
+	}
+}
+
 func (hr *HookRuntime) init() {
 
 	if hr.c0 == nil {
@@ -68,6 +154,7 @@ func (hr *HookRuntime) init() {
 func (hr *HookRuntime) WaitForEvent(ctx context.Context, h Hook) error {
 
 	hr.init()
+	hr.emit(EventHookStarting, nil)
 	hr.start(ctx, h)
 
 	// Either context was cancelled, or an error has occurred during Hook startup.
@@ -81,40 +168,53 @@ func (hr *HookRuntime) WaitForEvent(ctx context.Context, h Hook) error {
 		// Forward that c1 has stopped on shutdown.
 		hr.c1 <- nil
 
-		// If an error has occurred during Hook startup, we have to ignore Hook shutdown.
+		// Since Hook has returned from its startup, we have to ignore Hook shutdown.
+		hr.w0 = false
+
 		if err != nil {
-			hr.w0 = false
+			hr.emit(EventHookFailed, err)
 		}
 
 		return err
 	}
 }
 
-// Shutdown will gracefully shutdown the given hook, or kill it after timeout.
+// Shutdown will gracefully shutdown the given hook, or stop waiting for it after timeout: its goroutines are then
+// left running.
 // It will also synchronise that Start() and Stop() have finished.
+// Every error returned is a HookError, including a timeout.
 func (hr *HookRuntime) Shutdown(timeout time.Duration) []error {
 
 	t := time.Now()
 	failures := []error{}
 
-	// Wait for previous hook to gracefully shutdown, or kill it after timeout.
+	// Wait for previous hook to gracefully shutdown, or stop waiting for it after timeout.
 	for {
 		select {
 		case err := <-hr.c1:
 			if err != nil {
 				failures = append(failures, err)
+				hr.emit(EventHookFailed, err)
 			}
 			hr.w1 = false
 		case err := <-hr.c0:
 			if err != nil {
 				failures = append(failures, err)
+				hr.emit(EventHookFailed, err)
 			}
 			hr.w0 = false
 		case <-time.After(timeout - time.Since(t)):
-			return failures
+			err := wrapError(hr.name, PhaseTimeout, ErrShutdownTimeout)
+			hr.emit(EventHookTimeout, err)
+			return append(failures, err)
+		case <-hr.forced:
+			err := wrapError(hr.name, PhaseTimeout, ErrShutdownForced)
+			hr.emit(EventHookTimeout, err)
+			return append(failures, err)
 		}
 
 		if !hr.w1 && !hr.w0 {
+			hr.emit(EventHookStopped, nil)
 			return failures
 		}
 	}
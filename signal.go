@@ -11,29 +11,68 @@ var (
 	Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
 )
 
-// waitInterrupt will block until a shutdown notification is received.
-func (e *Engine) waitInterrupt() {
+// waitInterrupt will block until a shutdown notification is received, and it returns an event describing its trigger.
+// If the engine is already shutting down, because a hook has failed, false is returned instead.
+func (e *Engine) waitInterrupt() (Event, bool) {
 	select {
-	case <-e.interrupt:
+	case sig := <-e.interrupt:
+
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		if e.upgraded {
+			return Event{Type: EventShutdownRequested, Trigger: TriggerUpgrade}, true
+		}
+		if e.stopRequested {
+			return Event{Type: EventShutdownRequested, Trigger: TriggerStop}, true
+		}
+		return Event{Type: EventShutdownRequested, Trigger: TriggerSignal, Signal: sig}, true
+
 	case <-e.parent.Done():
+		return Event{Type: EventShutdownRequested, Trigger: TriggerContext, Err: e.parent.Err()}, true
+
+	case <-e.ctx.Done():
+		return Event{}, false
 	}
 }
 
-// waitShutdownNotification will forward a shutdown notification on engine when a stop signal is received or
-// when the parent context is terminated.
+// waitShutdownNotification will forward a shutdown notification on engine when a stop signal is received, when
+// the parent context is terminated or when a hook has failed. Then, it handles repeated signals until the engine
+// has shutdown.
 func (e *Engine) waitShutdownNotification() {
 
 	if len(e.signals) > 0 {
 		signal.Notify(e.interrupt, e.signals...)
+		defer signal.Stop(e.interrupt)
 	}
 
-	e.waitInterrupt()
+	event, ok := e.waitInterrupt()
+	if ok {
+
+		e.mutex.Lock()
+		e.transition(StateStopping, StateStarting, StateRunning)
+		e.traceShutdown(nil)
+		e.mutex.Unlock()
+
+		e.publish(event)
+
+		// Hooks are stopped once the pre-stop phase is done, while repeated signals are handled.
+		go func() {
+
+			e.drain()
+
+			if e.beforeShutdown != nil {
+				e.beforeShutdown()
+			}
+
+			e.cancel()
+
+		}()
 
-	if e.beforeShutdown != nil {
-		e.beforeShutdown()
 	}
 
-	e.cancel()
+	// Signals are handled until the engine has shutdown, so repeated signals could escalate the shutdown.
+	e.escalate()
 
 }
 
@@ -54,10 +93,12 @@ func AddSignal(signal os.Signal) Option {
 	})
 }
 
-// DisableSignal disables signal handling.
+// DisableSignal disables signal handling, including the reload and upgrade signals.
 func DisableSignal() Option {
 	return wrapOption(func(e *Engine) error {
 		e.signals = []os.Signal{}
+		e.reloadSignal = nil
+		e.upgradeSignal = nil
 		e.noSignal = true
 		return nil
 	})
@@ -0,0 +1,141 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// loggerKey is the context key of the structured logger given to a Hook.
+type loggerKey struct{}
+
+// messages contains the message logged for each kind of event.
+var messages = map[EventType]string{
+	EventEngineStarting:    "engine is starting",
+	EventHookStarting:      "hook is starting",
+	EventHookReady:         "hook is ready",
+	EventHookFailed:        "hook has failed",
+	EventReloadRequested:   "reload is requested",
+	EventHookReloaded:      "hook has been reloaded",
+	EventUpgradeRequested:  "upgrade is requested",
+	EventUpgradeFailed:     "upgrade has failed",
+	EventShutdownRequested: "shutdown is requested",
+	EventShutdownForced:    "shutdown is forced",
+	EventHookStopped:       "hook has stopped",
+	EventHookTimeout:       "hook has not stopped before timeout",
+	EventEngineStopped:     "engine has stopped",
+}
+
+// Slog sets a structured logger, which receives every lifecycle event of the engine at a level matching its
+// severity, with the hook name, phase, duration and cause as attributes, if any. Warnings, such as
+// ErrUnclaimedSocket, are logged at the warning level. It can be used along with Logger.
+//
+// Also, each hook receives a child logger, with its name as attribute: use HookLogger to obtain it.
+func Slog(logger *slog.Logger) Option {
+	return wrapOption(func(e *Engine) error {
+		e.slog = logger
+		return nil
+	})
+}
+
+// HookLogger returns the structured logger of the hook owning given context, which is given to Start() and Stop().
+// It's a child of the logger defined with Slog, with the hook name as attribute. Otherwise, the default logger is
+// returned.
+func HookLogger(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !ok || logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// hookLogger returns the structured logger given to given hook, if the engine has one.
+func (e *Engine) hookLogger(h *hookEntry) *slog.Logger {
+	if e.slog == nil {
+		return nil
+	}
+	return e.slog.With(slog.String("hook", h.name))
+}
+
+// logEvent forwards given event to the structured logger, if defined.
+func (e *Engine) logEvent(event Event) {
+
+	if e.slog == nil {
+		return
+	}
+
+	message, ok := messages[event.Type]
+	if !ok {
+		message = string(event.Type)
+	}
+
+	e.slog.LogAttrs(context.Background(), eventLevel(event), message, eventAttributes(event)...)
+}
+
+// eventLevel returns the level matching the severity of given event.
+func eventLevel(event Event) slog.Level {
+
+	switch event.Type {
+	case EventHookStarting:
+		return slog.LevelDebug
+	case EventHookFailed, EventHookTimeout, EventUpgradeFailed:
+		return slog.LevelError
+	case EventShutdownForced:
+		return slog.LevelWarn
+	case EventShutdownRequested:
+		if event.Trigger == TriggerFailure {
+			return slog.LevelWarn
+		}
+	case EventEngineStopped:
+		if event.Err != nil {
+			return slog.LevelError
+		}
+	}
+
+	return slog.LevelInfo
+}
+
+// eventAttributes returns the attributes of given event, if they're defined.
+func eventAttributes(event Event) []slog.Attr {
+
+	attributes := []slog.Attr{slog.String("event", string(event.Type))}
+
+	if event.Hook != "" {
+		attributes = append(attributes, slog.String("hook", event.Hook))
+	}
+
+	failure := &HookError{}
+	if errors.As(event.Err, &failure) {
+		attributes = append(attributes, slog.String("phase", string(failure.Phase)))
+	}
+
+	if event.Duration > 0 {
+		attributes = append(attributes, slog.Duration("duration", event.Duration))
+	}
+
+	if event.Trigger != "" {
+		attributes = append(attributes, slog.String("trigger", string(event.Trigger)))
+	}
+
+	if event.Signal != nil {
+		attributes = append(attributes, slog.String("signal", event.Signal.String()))
+	}
+
+	if event.Err != nil {
+		attributes = append(attributes, slog.Any("cause", event.Err))
+	}
+
+	return attributes
+}
+
+// logWarning forwards given warning to the structured logger, if defined.
+// Errors of hooks are already logged with their event.
+func (e *Engine) logWarning(err error) {
+
+	failure := &HookError{}
+	if e.slog == nil || errors.As(err, &failure) {
+		return
+	}
+
+	e.slog.LogAttrs(context.Background(), slog.LevelWarn, "warning", slog.Any("cause", err))
+}
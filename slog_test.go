@@ -0,0 +1,254 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlog(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle":  SlogLifecycle,
+		"Failure":    SlogFailure,
+		"HookLogger": SlogHookLogger,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// testRecord is a record received by testHandler, with its attributes.
+type testRecord struct {
+	level      slog.Level
+	message    string
+	attributes map[string]slog.Value
+}
+
+// testHandler records every log record.
+type testHandler struct {
+	mutex      *sync.Mutex
+	records    *[]testRecord
+	attributes []slog.Attr
+}
+
+func newTestHandler() *testHandler {
+	return &testHandler{mutex: &sync.Mutex{}, records: &[]testRecord{}}
+}
+
+func (h *testHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *testHandler) Handle(ctx context.Context, r slog.Record) error {
+
+	record := testRecord{level: r.Level, message: r.Message, attributes: map[string]slog.Value{}}
+	for _, attribute := range h.attributes {
+		record.attributes[attribute.Key] = attribute.Value
+	}
+	r.Attrs(func(attribute slog.Attr) bool {
+		record.attributes[attribute.Key] = attribute.Value
+		return true
+	})
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h *testHandler) WithAttrs(attributes []slog.Attr) slog.Handler {
+	return &testHandler{
+		mutex:      h.mutex,
+		records:    h.records,
+		attributes: append(append([]slog.Attr{}, h.attributes...), attributes...),
+	}
+}
+
+func (h *testHandler) WithGroup(name string) slog.Handler {
+	return h
+}
+
+// Find returns the first record with given event and hook.
+func (h *testHandler) Find(runtime *TestRuntime, event EventType, hook string) testRecord {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, record := range *h.records {
+		if record.attributes["event"].String() != string(event) {
+			continue
+		}
+		if value, ok := record.attributes["hook"]; hook == "" || (ok && value.String() == hook) {
+			return record
+		}
+	}
+
+	runtime.Error("Event %s of %q should have been logged: %+v", event, hook, *h.records)
+	return testRecord{}
+}
+
+// loggerHook logs a message with the logger given to Start().
+type loggerHook struct {
+	testHook
+}
+
+func (l *loggerHook) Start(ctx context.Context) error {
+	HookLogger(ctx).Info("connected")
+	return l.testHook.Start(ctx)
+}
+
+func SlogLifecycle(runtime *TestRuntime) {
+
+	handler := newTestHandler()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Slog(slog.New(handler)))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&readyHook{delay: 50 * time.Millisecond}, Name("database"), ReportsReady())
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	starting := handler.Find(runtime, EventHookStarting, "database")
+	if starting.level != slog.LevelDebug {
+		runtime.Error("Unexpected level: %s", starting.level)
+	}
+
+	ready := handler.Find(runtime, EventHookReady, "database")
+	if ready.level != slog.LevelInfo || ready.message != "hook is ready" {
+		runtime.Error("Unexpected record: %+v", ready)
+	}
+	runtime.InEpsilon(ready.attributes["duration"].Duration(), 50*time.Millisecond, 30*time.Millisecond,
+		"Hook has been ready with an unexpected amount of time...")
+
+	requested := handler.Find(runtime, EventShutdownRequested, "")
+	if requested.level != slog.LevelInfo || requested.attributes["trigger"].String() != string(TriggerStop) {
+		runtime.Error("Unexpected record: %+v", requested)
+	}
+
+	stopped := handler.Find(runtime, EventHookStopped, "database")
+	if _, ok := stopped.attributes["duration"]; !ok {
+		runtime.Error("Hook's shutdown duration should have been logged: %+v", stopped)
+	}
+
+	terminated := handler.Find(runtime, EventEngineStopped, "")
+	if terminated.level != slog.LevelInfo || terminated.attributes["duration"].Duration() <= 0 {
+		runtime.Error("Unexpected record: %+v", terminated)
+	}
+
+	runtime.Log("Engine has logged its lifecycle.")
+
+}
+
+func SlogFailure(runtime *TestRuntime) {
+
+	handler := newTestHandler()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Slog(slog.New(handler)))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{startError: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Name("database"))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "database", PhaseStart, hook.startError)
+
+	failed := handler.Find(runtime, EventHookFailed, "database")
+	if failed.level != slog.LevelError || failed.attributes["phase"].String() != string(PhaseStart) {
+		runtime.Error("Unexpected record: %+v", failed)
+	}
+
+	cause, ok := failed.attributes["cause"].Any().(error)
+	if !ok || !errors.Is(cause, hook.startError) {
+		runtime.Error("Unexpected cause: %+v", failed.attributes["cause"])
+	}
+
+	requested := handler.Find(runtime, EventShutdownRequested, "")
+	if requested.level != slog.LevelWarn {
+		runtime.Error("Unexpected level: %s", requested.level)
+	}
+
+	stopped := handler.Find(runtime, EventEngineStopped, "")
+	if stopped.level != slog.LevelError {
+		runtime.Error("Unexpected level: %s", stopped.level)
+	}
+
+	runtime.Log("Engine has logged its failure.")
+
+}
+
+func SlogHookLogger(runtime *TestRuntime) {
+
+	handler := newTestHandler()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Slog(slog.New(handler)))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&loggerHook{testHook{kill: make(chan struct{}, 1)}}, Name("database"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	found := false
+
+	handler.mutex.Lock()
+	for _, record := range *handler.records {
+		if record.message == "connected" && record.attributes["hook"].String() == "database" {
+			found = true
+		}
+	}
+	handler.mutex.Unlock()
+
+	if !found {
+		runtime.Error("Hook should have logged with its own logger")
+	}
+
+	if HookLogger(context.Background()) != slog.Default() {
+		runtime.Error("Default logger should be returned without hook context")
+	}
+
+	runtime.Log("Hook has logged with its own logger.")
+
+}
@@ -0,0 +1,280 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrSocketNotFound is returned when there is no inherited socket with the given name.
+	ErrSocketNotFound = errors.New("invalid socket: no inherited socket with this name")
+	// ErrUnclaimedSocket is forwarded to the Logger, as a warning, when an inherited socket hasn't been claimed by
+	// any hook once the engine is ready.
+	ErrUnclaimedSocket = errors.New("inherited socket has not been claimed by any hook")
+)
+
+// listenFdsStart is the first file descriptor passed by the socket activation protocol.
+var listenFdsStart = 3
+
+// socketsKey is the context key of the inherited sockets given to a Hook.
+type socketsKey struct{}
+
+// socket is a file descriptor inherited from the socket activation protocol.
+type socket struct {
+	name    string
+	file    *os.File
+	claimed bool
+}
+
+// active is a listener, or a packet-oriented connection, used by a hook.
+type active struct {
+	name string
+	conn io.Closer
+}
+
+// sockets contains every socket inherited by the process, and every socket used by hooks so they could be handed
+// off to a new process on upgrade.
+type sockets struct {
+	mutex  sync.Mutex
+	list   []*socket
+	active []active
+}
+
+// inherit returns the sockets passed to the process with the socket activation protocol of systemd, by parsing
+// LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES. These variables are then removed from the environment, so they
+// aren't inherited by child processes.
+func inherit() *sockets {
+
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	s := &sockets{}
+
+	// A process started by Upgrade() doesn't know its pid beforehand, so it's identified with its parent's pid.
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		ppid, err := strconv.Atoi(os.Getenv(upgradeParentEnv))
+		if err != nil || ppid != os.Getppid() {
+			return s
+		}
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return s
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := 0; i < count; i++ {
+
+		fd := listenFdsStart + i
+		name := fmt.Sprintf("LISTEN_FD_%d", fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		// Like sd_listen_fds, inherited sockets aren't inherited again by child processes, unless they're handed
+		// off explicitly.
+		closeOnExec(fd)
+
+		s.list = append(s.list, &socket{
+			name: name,
+			file: os.NewFile(uintptr(fd), name),
+		})
+	}
+
+	return s
+}
+
+// claim returns the file of an inherited socket with given name.
+// A socket that hasn't been claimed yet is preferred, so sockets sharing the same name are given to different hooks.
+func (s *sockets) claim(name string) (*os.File, error) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var found *socket
+	for _, socket := range s.list {
+		if socket.name == name && (found == nil || found.claimed) {
+			found = socket
+		}
+	}
+
+	if found == nil {
+		return nil, ErrSocketNotFound
+	}
+
+	found.claimed = true
+	return found.file, nil
+}
+
+// register keeps a reference on given socket used by a hook, so it could be handed off on upgrade.
+func (s *sockets) register(name string, conn io.Closer) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.active = append(s.active, active{name: name, conn: conn})
+}
+
+// handoff returns a duplicate of every socket used by hooks, with their name. Sockets that have been closed are
+// forgotten.
+func (s *sockets) handoff() ([]*os.File, []string) {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files := []*os.File{}
+	names := []string{}
+	list := s.active[:0]
+
+	for _, socket := range s.active {
+
+		filer, ok := socket.conn.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+
+		file, err := filer.File()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, file)
+		names = append(names, socket.name)
+		list = append(list, socket)
+	}
+
+	s.active = list
+
+	return files, names
+}
+
+// close releases every inherited socket. Listeners returned to hooks are still usable.
+func (s *sockets) close() {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, socket := range s.list {
+		_ = socket.file.Close()
+	}
+
+	s.list = nil
+}
+
+// lookup returns the inherited sockets from given context, or from the process if it's not a hook context.
+func lookup(ctx context.Context) *sockets {
+	s, ok := ctx.Value(socketsKey{}).(*sockets)
+	if !ok || s == nil {
+		return &sockets{}
+	}
+	return s
+}
+
+// Listener returns a listener on the inherited socket with given name, using the context given to Start().
+// If the process has no such socket, ErrSocketNotFound is returned.
+//
+// Sockets are inherited with the socket activation protocol of systemd: a socket is named after the
+// FileDescriptorName= of its unit, or LISTEN_FD_<fd> if undefined. Each call returns a new listener, so a hook
+// could claim its socket again once restarted. Inherited sockets are released once the engine has stopped.
+func Listener(ctx context.Context, name string) (net.Listener, error) {
+
+	s := lookup(ctx)
+
+	file, err := s.claim(name)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, err
+	}
+
+	s.register(name, listener)
+	return listener, nil
+}
+
+// PacketConn returns a packet-oriented connection on the inherited socket with given name, using the context given
+// to Start(). If the process has no such socket, ErrSocketNotFound is returned. See Listener for further information.
+func PacketConn(ctx context.Context, name string) (net.PacketConn, error) {
+
+	s := lookup(ctx)
+
+	file, err := s.claim(name)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.FilePacketConn(file)
+	if err != nil {
+		return nil, err
+	}
+
+	s.register(name, conn)
+	return conn, nil
+}
+
+// Listen returns a listener on the inherited socket with given name, using the context given to Start().
+// If the process has no such socket, it listens on given network address instead.
+// In both cases, the listener is handed off to the new process on upgrade, under the given name.
+func Listen(ctx context.Context, name, network, address string) (net.Listener, error) {
+
+	listener, err := Listener(ctx, name)
+	if !errors.Is(err, ErrSocketNotFound) {
+		return listener, err
+	}
+
+	listener, err = net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup(ctx).register(name, listener)
+	return listener, nil
+}
+
+// ListenPacket returns a packet-oriented connection on the inherited socket with given name, using the context
+// given to Start(). If the process has no such socket, it listens on given network address instead.
+// In both cases, the connection is handed off to the new process on upgrade, under the given name.
+func ListenPacket(ctx context.Context, name, network, address string) (net.PacketConn, error) {
+
+	conn, err := PacketConn(ctx, name)
+	if !errors.Is(err, ErrSocketNotFound) {
+		return conn, err
+	}
+
+	conn, err = net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup(ctx).register(name, conn)
+	return conn, nil
+}
+
+// unclaimed forwards a warning to the logger for every inherited socket that hasn't been claimed by any hook.
+// It must be called with the engine's mutex.
+func (e *Engine) unclaimed() {
+
+	e.sockets.mutex.Lock()
+	defer e.sockets.mutex.Unlock()
+
+	for _, socket := range e.sockets.list {
+		if !socket.claimed {
+			e.log(fmt.Errorf("%w: %s", ErrUnclaimedSocket, socket.name))
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package lemon
+
+// closeOnExec is a no-op, since socket activation isn't supported on this system.
+func closeOnExec(fd int) {}
@@ -0,0 +1,300 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestSocket(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Listener":    SocketListener,
+		"PacketConn":  SocketPacketConn,
+		"Fallback":    SocketFallback,
+		"Unclaimed":   SocketUnclaimed,
+		"OtherPID":    SocketWithOtherPID,
+		"CloseOnExec": SocketCloseOnExec,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// socketHook claims an inherited socket when it starts.
+type socketHook struct {
+	name     string
+	packet   bool
+	address  net.Addr
+	claimErr error
+}
+
+func (s *socketHook) Start(ctx context.Context) error {
+
+	if s.packet {
+		conn, err := PacketConn(ctx, s.name)
+		if err != nil {
+			s.claimErr = err
+			return nil
+		}
+		defer conn.Close()
+		s.address = conn.LocalAddr()
+	} else {
+		listener, err := Listen(ctx, s.name, "tcp", "127.0.0.1:0")
+		if err != nil {
+			s.claimErr = err
+			return nil
+		}
+		defer listener.Close()
+		s.address = listener.Addr()
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func (s *socketHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Activate passes given file to the process like the socket activation protocol, with given name.
+// The engine owns a duplicate of its descriptor, so given file is closed.
+func (r *TestRuntime) Activate(file *os.File, name string) func() {
+
+	fd, err := syscall.Dup(int(file.Fd()))
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+	_ = file.Close()
+
+	start := listenFdsStart
+	listenFdsStart = fd
+
+	env := map[string]string{
+		"LISTEN_PID":     strconv.Itoa(os.Getpid()),
+		"LISTEN_FDS":     "1",
+		"LISTEN_FDNAMES": name,
+	}
+
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			r.Error("An error wasn't expected: %s", err)
+		}
+	}
+
+	return func() {
+		listenFdsStart = start
+		for key := range env {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+// RunSockets starts an engine with given hooks, and stops it once it's ready.
+func (r *TestRuntime) RunSockets(options []Option, hooks ...Hook) {
+
+	engine, err := New(r.Context(), append(options, DisableSignal())...)
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		r.Error("Engine must be defined")
+	}
+
+	if os.Getenv("LISTEN_FDS") != "" {
+		r.Error("Environment should have been cleaned")
+	}
+
+	for _, hook := range hooks {
+		engine.Register(hook)
+	}
+
+	result := r.StartEngine(engine)
+
+	err = engine.WaitReady(r.Context())
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+}
+
+func SocketListener(runtime *TestRuntime) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer listener.Close()
+
+	file, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	cleanup := runtime.Activate(file, "http")
+	defer cleanup()
+
+	hook := &socketHook{name: "http"}
+	runtime.RunSockets(nil, hook)
+
+	if hook.claimErr != nil {
+		runtime.Error("An error wasn't expected: %s", hook.claimErr)
+	}
+	if hook.address == nil || hook.address.String() != listener.Addr().String() {
+		runtime.Error("Hook should listen on inherited socket %s: %v", listener.Addr(), hook.address)
+	}
+
+	runtime.Log("Hook has listened on inherited socket %s", hook.address)
+
+}
+
+func SocketPacketConn(runtime *TestRuntime) {
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer conn.Close()
+
+	file, err := conn.(*net.UDPConn).File()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	cleanup := runtime.Activate(file, "dns")
+	defer cleanup()
+
+	hook := &socketHook{name: "dns", packet: true}
+	runtime.RunSockets(nil, hook)
+
+	if hook.claimErr != nil {
+		runtime.Error("An error wasn't expected: %s", hook.claimErr)
+	}
+	if hook.address == nil || hook.address.String() != conn.LocalAddr().String() {
+		runtime.Error("Hook should listen on inherited socket %s: %v", conn.LocalAddr(), hook.address)
+	}
+
+	runtime.Log("Hook has listened on inherited socket %s", hook.address)
+
+}
+
+func SocketFallback(runtime *TestRuntime) {
+
+	hook1 := &socketHook{name: "http"}
+	hook2 := &socketHook{name: "dns", packet: true}
+
+	runtime.RunSockets(nil, hook1, hook2)
+
+	if hook1.claimErr != nil || hook1.address == nil {
+		runtime.Error("Hook should have listened on its own address: %v", hook1.claimErr)
+	}
+	if !errors.Is(hook2.claimErr, ErrSocketNotFound) {
+		runtime.Error("Unexpected error: %v", hook2.claimErr)
+	}
+
+	runtime.Log("Hook has listened on its own address %s", hook1.address)
+
+}
+
+func SocketUnclaimed(runtime *TestRuntime) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer listener.Close()
+
+	file, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	cleanup := runtime.Activate(file, "http")
+	defer cleanup()
+
+	logger := &testLogger{}
+	hook := &socketHook{name: "grpc"}
+
+	runtime.RunSockets([]Option{Logger(logger.Handle)}, hook)
+
+	failures := logger.Failures()
+	if len(failures) != 1 || !errors.Is(failures[0], ErrUnclaimedSocket) {
+		runtime.Error("A warning was expected: %v", failures)
+	}
+
+	runtime.Log("Engine has warned about an unclaimed socket: %s", failures[0])
+
+}
+
+func SocketWithOtherPID(runtime *TestRuntime) {
+
+	// These descriptors belong to another process, so they must not be used.
+	env := map[string]string{
+		"LISTEN_PID":     strconv.Itoa(os.Getpid() + 1),
+		"LISTEN_FDS":     "1",
+		"LISTEN_FDNAMES": "http",
+	}
+
+	for key, value := range env {
+		err := os.Setenv(key, value)
+		if err != nil {
+			runtime.Error("An error wasn't expected: %s", err)
+		}
+	}
+
+	hook := &socketHook{name: "http"}
+	runtime.RunSockets(nil, hook)
+
+	if hook.claimErr != nil || hook.address == nil {
+		runtime.Error("Hook should have listened on its own address: %v", hook.claimErr)
+	}
+
+	runtime.Log("Engine has ignored sockets of another process.")
+
+}
+
+func SocketCloseOnExec(runtime *TestRuntime) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer listener.Close()
+
+	file, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	restore := runtime.Activate(file, "http")
+	defer restore()
+
+	s := inherit()
+	defer s.close()
+
+	if len(s.list) != 1 {
+		runtime.Error("A socket should have been inherited")
+	}
+
+	flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, s.list[0].file.Fd(), syscall.F_GETFD, 0)
+	if errno != 0 {
+		runtime.Error("An error wasn't expected: %s", errno)
+	}
+	if flags&syscall.FD_CLOEXEC == 0 {
+		runtime.Error("Inherited socket should be close-on-exec")
+	}
+
+	runtime.Log("Inherited socket isn't inherited by child processes.")
+
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package lemon
+
+import (
+	"syscall"
+)
+
+// closeOnExec marks given inherited descriptor as close-on-exec, so it's not leaked to child processes.
+func closeOnExec(fd int) {
+	syscall.CloseOnExec(fd)
+}
@@ -0,0 +1,112 @@
+package lemon
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrStageNotFound is returned when a hook is registered in a stage which is not declared on the engine.
+	ErrStageNotFound = errors.New("invalid stage: a hook is registered in an undeclared stage")
+	// ErrStageDuplicate is returned when a stage is declared more than once.
+	ErrStageDuplicate = errors.New("invalid stage: stage is declared more than once")
+)
+
+// stage is a group of hooks started concurrently, once every hook of the previous stages is ready.
+type stage struct {
+	name    string
+	timeout time.Duration
+}
+
+// Stage declares a startup stage, such as "infrastructure", "services" or "ingress". Stages are started in the
+// order of their declaration, and hooks are registered in a stage with InStage.
+//
+// Hooks of a stage are started concurrently, once every hook of the previous stages is ready. They are stopped in
+// the reverse order: a stage is stopped once every hook of the next stages has shutdown. If a hook fails, the
+// engine shutdowns, so the previous stages are stopped and the next ones are never started.
+//
+// The given timeout is the shutdown budget of the stage: a hook of this stage is given at most this amount of time
+// to gracefully shut down, instead of the engine's timeout.
+func Stage(name string, timeout time.Duration) Option {
+	return wrapOption(func(e *Engine) error {
+
+		if timeout <= 0 {
+			return ErrTimeout
+		}
+
+		if e.findStage(name) != -1 {
+			return ErrStageDuplicate
+		}
+
+		e.stages = append(e.stages, stage{name: name, timeout: timeout})
+		return nil
+
+	})
+}
+
+// InStage registers the hook in given stage, declared with Stage.
+// If the stage isn't declared, the engine will fail to start with ErrStageNotFound.
+// A hook without stage is started right away, or once its dependencies are ready.
+//
+// If a hook is registered while the engine is running, the hooks of the next stages that have already been launched
+// don't wait for it to be ready: however, they're stopped before it.
+func InStage(name string) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+		h.stage = name
+	})
+}
+
+// findStage returns the position of given stage, or -1 if it's not declared.
+func (e *Engine) findStage(name string) int {
+	for i := range e.stages {
+		if e.stages[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// linkStage makes given hook depend on every hook of the previous stages. If hooks of the next stages have
+// already been launched, they don't wait for given hook to start, since their requirements are already resolved:
+// however, they're stopped before it.
+// It must be called with the engine's mutex.
+func (e *Engine) linkStage(h *hookEntry) error {
+
+	if h.stage == "" {
+		return nil
+	}
+
+	position := e.findStage(h.stage)
+	if position == -1 {
+		return wrapError(h.name, PhaseStart, ErrStageNotFound)
+	}
+
+	h.budget = e.stages[position].timeout
+
+	for _, other := range e.hooks {
+
+		if other == h || other.stage == "" {
+			continue
+		}
+
+		switch i := e.findStage(other.stage); {
+		case i != -1 && i < position:
+			require(h, other)
+		case i > position && other.cancel != nil:
+			h.dependents = append(h.dependents, other)
+		}
+	}
+
+	return nil
+}
+
+// require declares that given hook depends on given dependency, unless it's already the case.
+func require(h *hookEntry, dependency *hookEntry) {
+	for _, entry := range h.requires {
+		if entry == dependency {
+			return
+		}
+	}
+	h.requires = append(h.requires, dependency)
+	dependency.dependents = append(dependency.dependents, h)
+}
@@ -0,0 +1,233 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStage(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Order":     StageOrder,
+		"Failure":   StageFailure,
+		"Budget":    StageBudget,
+		"NotFound":  StageNotFound,
+		"Duplicate": StageDuplicate,
+		"Register":  StageRegister,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+func StageOrder(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx,
+		Stage("infrastructure", time.Second),
+		Stage("services", time.Second),
+		Stage("ingress", time.Second),
+	)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	journal := &testJournal{}
+	database := &orderHook{id: "database", journal: journal}
+	cache := &orderHook{id: "cache", journal: journal}
+	users := &orderHook{id: "users", journal: journal}
+	server := &orderHook{id: "server", journal: journal}
+
+	// Register hooks in the wrong order on purpose.
+	engine.Register(server, InStage("ingress"), ReportsReady())
+	engine.Register(users, InStage("services"), ReportsReady())
+	engine.Register(cache, InStage("infrastructure"), ReportsReady())
+	engine.Register(database, InStage("infrastructure"), ReportsReady())
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasOrder(journal, "start:database", "start:users")
+	runtime.HasOrder(journal, "start:cache", "start:users")
+	runtime.HasOrder(journal, "start:users", "start:server")
+	runtime.HasOrder(journal, "stop:server", "stop:users")
+	runtime.HasOrder(journal, "stop:users", "stop:database")
+	runtime.HasOrder(journal, "stop:users", "stop:cache")
+
+	runtime.Log("Engine has started and stopped hooks following their stages.")
+
+}
+
+func StageFailure(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(),
+		Stage("infrastructure", time.Second),
+		Stage("services", time.Second),
+		Stage("ingress", time.Second),
+	)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{startError: errors.New("an error has occurred: foobar")}
+	hook3 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, InStage("infrastructure"))
+	// Hook2 never reports its readiness, so it fails before the next stage is started.
+	engine.Register(hook2, InStage("services"), ReportsReady())
+	engine.Register(hook3, InStage("ingress"))
+
+	err = engine.Start()
+	runtime.IsHookError(err, "*lemon.testHook#2", PhaseStart, hook2.startError)
+
+	runtime.HasLifecycle(hook1, "hook1")
+	runtime.HasStarted(hook2, "hook2")
+
+	hook3.mutex.Lock()
+	if hook3.startCalled {
+		runtime.Error("Hook3 shouldn't have started")
+	}
+	hook3.mutex.Unlock()
+
+	runtime.Log("Engine has stopped previous stages and never started next ones: %s", err)
+
+}
+
+func StageBudget(runtime *TestRuntime) {
+
+	kill := 200 * time.Millisecond
+	budget := 300 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+	maximum := kill + budget
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx, Timeout(3*time.Second), Stage("services", budget))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}), stopTimeout: true}
+	hook2 := &testHook{kill: make(chan struct{}), stopTimeout: true}
+
+	engine.Register(hook1, InStage("services"))
+	engine.Register(hook2, InStage("services"), ShutdownTimeout(time.Minute))
+
+	now := time.Now()
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InEpsilon(delta, maximum, epsilon, "Engine has shutdown with an unexpected amount of time...")
+
+	runtime.Log("Engine has shutdown with the stage's budget: %s.", delta)
+
+}
+
+func StageNotFound(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), Stage("services", time.Second))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook, InStage("ingress"))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrStageNotFound) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.startCalled {
+		runtime.Error("Hook shouldn't have been started.")
+	}
+
+	runtime.Log("Engine has refused to start with an undeclared stage.")
+
+}
+
+func StageDuplicate(runtime *TestRuntime) {
+
+	_, err := New(runtime.Context(), Stage("services", time.Second), Stage("services", time.Second))
+	if err != ErrStageDuplicate {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	_, err = New(runtime.Context(), Stage("services", 0))
+	if err != ErrTimeout {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine has refused invalid stages.")
+
+}
+
+func StageRegister(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(),
+		Stage("infrastructure", time.Second),
+		Stage("ingress", time.Second),
+	)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	journal := &testJournal{}
+	database := &readyHook{delay: 100 * time.Millisecond}
+	server := &orderHook{id: "server", journal: journal}
+	cache := &orderHook{id: "cache", journal: journal}
+
+	engine.Register(database, Name("database"), InStage("infrastructure"), ReportsReady())
+	engine.Register(server, Name("server"), InStage("ingress"), ReportsReady())
+
+	result := runtime.StartEngine(engine)
+	runtime.WaitHookState(engine, "database", StateStarting)
+
+	// The server is waiting for the database, while a hook is registered in its previous stage.
+	engine.Register(cache, Name("cache"), InStage("infrastructure"), ReportsReady())
+
+	runtime.WaitHookState(engine, "cache", StateRunning)
+	runtime.WaitHookState(engine, "server", StateRunning)
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasOrder(journal, "start:cache", "start:server")
+	runtime.HasOrder(journal, "stop:server", "stop:cache")
+
+	runtime.Log("Engine has stopped a hook registered in a previous stage after the next stages.")
+
+}
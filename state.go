@@ -0,0 +1,207 @@
+package lemon
+
+import (
+	"errors"
+	"time"
+)
+
+// State is a step of the engine, or hook, state machine.
+//
+// An engine is idle until it starts. Then, it's starting until every hook is ready, and it's running until a
+// shutdown is required. Finally, it's stopping until every hook has shutdown, and it's stopped.
+//
+// A hook follows the same steps, except that it's starting again when it's restarted, that it's unhealthy while
+// it fails its health checks, and that it's failed instead of stopped if it has triggered the engine's shutdown
+// with an error.
+type State string
+
+const (
+	// StateIdle is used when the engine, or hook, hasn't started yet.
+	StateIdle = State("idle")
+	// StateStarting is used when the engine, or hook, is starting and isn't ready yet.
+	StateStarting = State("starting")
+	// StateRunning is used when the engine, or hook, is ready.
+	StateRunning = State("running")
+	// StateUnhealthy is used when a hook was ready, but it has failed its health checks.
+	StateUnhealthy = State("unhealthy")
+	// StateStopping is used when the engine, or hook, is shutting down.
+	StateStopping = State("stopping")
+	// StateStopped is used when the engine, or hook, has shutdown.
+	StateStopped = State("stopped")
+	// StateFailed is used when a hook has shutdown after a failure.
+	StateFailed = State("failed")
+)
+
+var (
+	// ErrAlreadyStarted is returned when the engine is started more than once.
+	ErrAlreadyStarted = errors.New("invalid state: engine has already been started")
+	// ErrAlreadyStopped is returned when the engine is stopped after its shutdown.
+	ErrAlreadyStopped = errors.New("invalid state: engine has already been stopped")
+)
+
+// HookStatus is a snapshot of a hook state.
+type HookStatus struct {
+	// Name is the name of the hook.
+	Name string
+	// State is the current state of the hook.
+	State State
+	// Since is when the hook has entered its current state.
+	Since time.Time
+	// Started is when the hook has started for the last time. It's undefined if the hook has never started.
+	Started time.Time
+	// Restarts is the number of times the hook has been restarted.
+	Restarts int
+	// Err is the last error that has occurred during the hook lifecycle, if any.
+	Err error
+}
+
+// State returns the current state of the engine.
+func (e *Engine) State() State {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.state == "" {
+		return StateIdle
+	}
+
+	return e.state
+}
+
+// Hooks returns a snapshot of every registered hook state, in their registration order.
+func (e *Engine) Hooks() []HookStatus {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	hooks := make([]HookStatus, 0, len(e.hooks))
+	for _, h := range e.hooks {
+		hooks = append(hooks, h.status())
+	}
+
+	return hooks
+}
+
+// transition changes the engine state, if its current state is one of given states.
+// It must be called with the engine's mutex.
+func (e *Engine) transition(to State, from ...State) bool {
+
+	current := e.state
+	if current == "" {
+		current = StateIdle
+	}
+
+	for i := range from {
+		if current == from[i] {
+			e.state = to
+			return true
+		}
+	}
+
+	return false
+}
+
+// status returns a snapshot of the hook state.
+// It must be called with the engine's mutex.
+func (h *hookEntry) status() HookStatus {
+
+	status := HookStatus{
+		Name:     h.name,
+		State:    h.state,
+		Since:    h.since,
+		Started:  h.started,
+		Restarts: h.restarts,
+	}
+
+	if status.State == "" {
+		status.State = StateIdle
+	}
+
+	if len(h.failures) > 0 {
+		status.Err = h.failures[len(h.failures)-1]
+	}
+
+	return status
+}
+
+// transition changes the hook state, and records when the hook has started, has been ready, or has shutdown.
+// It must be called with the engine's mutex.
+func (h *hookEntry) transition(to State) {
+
+	from := h.state
+	h.state = to
+	h.since = time.Now()
+
+	switch to {
+	case StateStarting:
+		if h.first.IsZero() {
+			h.first = h.since
+		}
+		h.started = h.since
+	case StateRunning:
+		if from == StateStarting {
+			h.readied = h.since
+		}
+	case StateStopping:
+		h.stopping = h.since
+	case StateStopped, StateFailed:
+		h.stopped = h.since
+	}
+}
+
+// update changes the state of given hook.
+func (e *Engine) update(h *hookEntry, to State) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	h.transition(to)
+}
+
+// settle changes the state of given hook once it has shutdown: it has failed if its Start() has returned given
+// error, or if it wasn't ready before its startup timeout.
+func (e *Engine) settle(h *hookEntry, err error) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err != nil || h.late {
+		h.transition(StateFailed)
+	} else {
+		h.transition(StateStopped)
+	}
+}
+
+// starting changes the state of given hook to starting, and counts its restarts.
+func (e *Engine) starting(h *hookEntry) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !h.started.IsZero() {
+		h.restarts++
+	}
+
+	h.transition(StateStarting)
+}
+
+// running changes the state of given hook to running, if it's starting or unhealthy.
+// It's executed whenever the hook reports its readiness, or a successful health check.
+func (e *Engine) running(h *hookEntry) {
+
+	e.mutex.Lock()
+	ok := h.state == StateStarting || h.state == StateUnhealthy
+	if ok {
+		h.transition(StateRunning)
+	}
+	// The time to be ready is only known if the hook was starting.
+	duration := time.Duration(0)
+	if ok && h.readied == h.since {
+		duration = h.readied.Sub(h.started)
+	}
+	e.mutex.Unlock()
+
+	if ok {
+		e.publish(Event{Type: EventHookReady, Hook: h.name, Duration: duration})
+	}
+}
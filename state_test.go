@@ -0,0 +1,211 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestState(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle": StateLifecycle,
+		"Failed":    StateHookFailed,
+		"Restarts":  StateHookRestarts,
+		"Invalid":   StateInvalidConfiguration,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+func (r *TestRuntime) HasState(engine *Engine, expected State) {
+	if engine.State() != expected {
+		r.Error("Unexpected engine state: %s", engine.State())
+	}
+}
+
+func (r *TestRuntime) HasHookStates(engine *Engine, expected ...State) {
+	hooks := engine.Hooks()
+	if len(hooks) != len(expected) {
+		r.Error("Unexpected hooks: %+v", hooks)
+	}
+	for i := range hooks {
+		if hooks[i].State != expected[i] {
+			r.Error("Unexpected state for hook %s: %s", hooks[i].Name, hooks[i].State)
+		}
+	}
+}
+
+func StateLifecycle(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook1"))
+	engine.Register(&readyHook{delay: 100 * time.Millisecond}, Name("hook2"), ReportsReady())
+
+	runtime.HasState(engine, StateIdle)
+	runtime.HasHookStates(engine, StateIdle, StateIdle)
+
+	result := runtime.StartEngine(engine)
+
+	time.Sleep(50 * time.Millisecond)
+	runtime.HasState(engine, StateStarting)
+	runtime.HasHookStates(engine, StateRunning, StateStarting)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasState(engine, StateRunning)
+	runtime.HasHookStates(engine, StateRunning, StateRunning)
+
+	err = engine.Start()
+	if err != ErrAlreadyStarted {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	err = engine.Stop()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasState(engine, StateStopped)
+	runtime.HasHookStates(engine, StateStopped, StateStopped)
+
+	for _, hook := range engine.Hooks() {
+		if hook.Started.IsZero() || hook.Since.Before(hook.Started) {
+			runtime.Error("Unexpected timestamps for hook %s: %+v", hook.Name, hook)
+		}
+	}
+
+	err = engine.Stop()
+	if err != ErrAlreadyStopped {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	err = engine.Start()
+	if err != ErrAlreadyStarted {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine has followed its state machine.")
+
+}
+
+func StateHookFailed(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{startError: errors.New("an error has occurred: foobar")}
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, Name("hook1"))
+	engine.Register(hook, Name("hook2"))
+
+	err = engine.Start()
+	if err == nil {
+		runtime.Error("An error was expected")
+	}
+
+	runtime.HasState(engine, StateStopped)
+	runtime.HasHookStates(engine, StateStopped, StateFailed)
+
+	status := engine.Hooks()[1]
+	if !errors.Is(status.Err, hook.startError) {
+		runtime.Error("Unexpected error: %v", status.Err)
+	}
+
+	runtime.Log("Engine has reported a failed hook.")
+
+}
+
+func StateHookRestarts(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &flakyHook{failures: 2, err: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Restart(RestartPolicy{
+		Mode:    RestartOnFailure,
+		Backoff: 10 * time.Millisecond,
+	}))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	status := engine.Hooks()[0]
+	if status.Restarts != 2 {
+		runtime.Error("Unexpected number of restarts: %d", status.Restarts)
+	}
+	if status.State != StateStopped {
+		runtime.Error("Unexpected state: %s", status.State)
+	}
+
+	runtime.Log("Engine has reported restarts of a hook.")
+
+}
+
+func StateInvalidConfiguration(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook1, DependsOn(hook2))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrMissingDependency) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.HasState(engine, StateIdle)
+
+	engine.Register(hook2)
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.HasState(engine, StateStopped)
+
+	runtime.Log("Engine can start once its configuration is fixed.")
+
+}
@@ -0,0 +1,188 @@
+package lemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Systemd enables the notification protocol of systemd, for a service unit with Type=notify.
+//
+// Once every hook is ready, the engine sends READY=1, and it sends STOPPING=1 when a shutdown is required.
+// Also, a STATUS= line summarises the state of hooks whenever it changes. If the watchdog is enabled for the unit,
+// the engine sends WATCHDOG=1 at half its interval, while it's starting or available.
+//
+// On Upgrade, the engine sends MAINPID= with the pid of the new process once it's ready, before shutting down.
+// Since the new process notifies systemd before being its main process, the unit must define NotifyAccess=all.
+//
+// This option has no effect if the engine isn't supervised by systemd, i.e. if NOTIFY_SOCKET isn't defined.
+func Systemd() Option {
+	return wrapOption(func(e *Engine) error {
+		e.systemd = true
+		return nil
+	})
+}
+
+// notifier sends notifications to systemd.
+type notifier struct {
+	conn *net.UnixConn
+	// watchdog is the interval between two watchdog notifications, if the watchdog is enabled.
+	watchdog time.Duration
+	// status is the last status sent.
+	status string
+}
+
+// newNotifier returns a notifier using the socket defined by systemd, or nil if there is no such socket.
+func newNotifier() *notifier {
+
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil
+	}
+
+	n := &notifier{conn: conn}
+
+	pid, err := strconv.Atoi(os.Getenv("WATCHDOG_PID"))
+	if err == nil && pid != os.Getpid() {
+		// Watchdog is enabled for another process.
+		return n
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond / 2
+	}
+
+	return n
+}
+
+// send forwards given state to systemd. Errors are ignored, since systemd may not listen anymore.
+func (n *notifier) send(state string) {
+	_, _ = n.conn.Write([]byte(state))
+}
+
+// notifyMainPID reports to systemd that given process is the new main process of the service, if enabled.
+func (e *Engine) notifyMainPID(pid int) {
+
+	if !e.systemd {
+		return
+	}
+
+	n := newNotifier()
+	if n == nil {
+		return
+	}
+	defer n.conn.Close()
+
+	n.send(fmt.Sprintf("MAINPID=%d", pid))
+}
+
+// notifySystemd will forward the engine's lifecycle to systemd, if enabled, until the engine has stopped.
+// It returns a function that blocks until the last notification has been sent.
+func (e *Engine) notifySystemd() func() {
+
+	if !e.systemd {
+		return func() {}
+	}
+
+	n := newNotifier()
+	if n == nil {
+		return func() {}
+	}
+
+	events, unsubscribe := e.Subscribe(256)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer n.conn.Close()
+		e.forward(n, events)
+	}()
+
+	return func() {
+		unsubscribe()
+		<-done
+	}
+}
+
+// forward sends notifications to systemd from given events, until the engine has stopped.
+func (e *Engine) forward(n *notifier, events <-chan Event) {
+
+	ready := e.ready
+
+	var watchdog <-chan time.Time
+	if n.watchdog > 0 {
+		ticker := time.NewTicker(n.watchdog)
+		defer ticker.Stop()
+		watchdog = ticker.C
+	}
+
+	for {
+		select {
+		case <-ready:
+			ready = nil
+			if e.State() == StateRunning {
+				n.send("READY=1")
+			}
+			e.notifyStatus(n)
+
+		case <-watchdog:
+			e.ping(n)
+
+		case event, ok := <-events:
+			if !ok || !e.forwardEvent(n, event) {
+				return
+			}
+		}
+	}
+}
+
+// ping sends a keep-alive notification to the watchdog of systemd, while the engine is starting or available.
+func (e *Engine) ping(n *notifier) {
+	if e.State() == StateStarting || e.available() {
+		n.send("WATCHDOG=1")
+	}
+}
+
+// forwardEvent sends the notification of given event to systemd, if any.
+// It returns false once the engine has stopped.
+func (e *Engine) forwardEvent(n *notifier, event Event) bool {
+
+	switch event.Type {
+	case EventShutdownRequested:
+		n.send("STOPPING=1")
+	case EventEngineStopped:
+		e.notifyStatus(n)
+		return false
+	case EventHookStarting, EventHookReady, EventHookFailed, EventHookStopped, EventHookTimeout:
+		e.notifyStatus(n)
+	}
+
+	return true
+}
+
+// notifyStatus sends a summary of hooks state to systemd, if it has changed.
+func (e *Engine) notifyStatus(n *notifier) {
+
+	hooks := e.Hooks()
+
+	running := 0
+	for _, h := range hooks {
+		if h.State == StateRunning {
+			running++
+		}
+	}
+
+	status := fmt.Sprintf("STATUS=%s: %d/%d hooks running", e.State(), running, len(hooks))
+	if status != n.status {
+		n.status = status
+		n.send(status)
+	}
+}
@@ -0,0 +1,251 @@
+package lemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSystemd(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Notify":   SystemdNotify,
+		"Watchdog": SystemdWatchdog,
+		"Disabled": SystemdWithoutSocket,
+		"Upgrade":  SystemdUpgrade,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// ListenNotify creates a socket, like systemd, and returns a channel which receives every notification.
+func (r *TestRuntime) ListenNotify() (<-chan string, func()) {
+
+	directory, err := os.MkdirTemp("", "lemon")
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	socket := filepath.Join(directory, "notify.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	err = os.Setenv("NOTIFY_SOCKET", socket)
+	if err != nil {
+		r.Error("An error wasn't expected: %s", err)
+	}
+
+	messages := make(chan string, 256)
+
+	go func() {
+		defer close(messages)
+		buffer := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buffer)
+			if err != nil {
+				return
+			}
+			messages <- string(buffer[:n])
+		}
+	}()
+
+	return messages, func() {
+		_ = os.Unsetenv("NOTIFY_SOCKET")
+		_ = conn.Close()
+		_ = os.RemoveAll(directory)
+	}
+}
+
+// HasNotifications verifies that given notifications have been received in order.
+func (r *TestRuntime) HasNotifications(messages <-chan string, expected ...string) []string {
+
+	received := []string{}
+	timeout := time.After(time.Second)
+
+	for i := 0; i < len(expected); {
+		select {
+		case message := <-messages:
+			received = append(received, message)
+			if strings.HasPrefix(message, expected[i]) {
+				i++
+			}
+		case <-timeout:
+			r.Error("Notifications %v should have been received in order: %v", expected, received)
+			return received
+		}
+	}
+
+	return received
+}
+
+func SystemdNotify(runtime *TestRuntime) {
+
+	messages, cleanup := runtime.ListenNotify()
+	defer cleanup()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Systemd())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	runtime.HasNotifications(messages, "READY=1")
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	received := runtime.HasNotifications(messages, "STOPPING=1", "STATUS=stopped: 0/2 hooks running")
+	for _, message := range received {
+		if message == "WATCHDOG=1" {
+			runtime.Error("Watchdog shouldn't be notified")
+		}
+	}
+
+	runtime.Log("Engine has notified systemd: %v", received)
+
+}
+
+func SystemdWatchdog(runtime *TestRuntime) {
+
+	messages, cleanup := runtime.ListenNotify()
+	defer cleanup()
+
+	err := os.Setenv("WATCHDOG_USEC", "20000")
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer func() {
+		_ = os.Unsetenv("WATCHDOG_USEC")
+	}()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Systemd())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	runtime.HasNotifications(messages, "READY=1", "WATCHDOG=1", "WATCHDOG=1")
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has notified systemd watchdog.")
+
+}
+
+func SystemdWithoutSocket(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal(), Systemd())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)})
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has started without systemd.")
+
+}
+
+func SystemdUpgrade(runtime *TestRuntime) {
+
+	messages, cleanup := runtime.ListenNotify()
+	defer cleanup()
+
+	restore := runtime.Reexec("serve")
+	defer restore()
+
+	engine, err := New(runtime.Context(), DisableSignal(), Systemd())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	address := <-hook.address
+
+	runtime.HasNotifications(messages, "READY=1")
+
+	err = engine.Upgrade(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	// The new process is the main process before this one is stopping.
+	received := runtime.HasNotifications(messages, "MAINPID=", "STOPPING=1")
+
+	mainpid := ""
+	for _, message := range received {
+		if strings.HasPrefix(message, "MAINPID=") {
+			mainpid = message
+		}
+	}
+	if mainpid == fmt.Sprintf("MAINPID=%d", os.Getpid()) {
+		runtime.Error("Unexpected main process: %s", mainpid)
+	}
+
+	// Release the new process.
+	conn, err := net.DialTimeout("tcp", address.String(), time.Second)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	_ = conn.Close()
+
+	runtime.Log("Engine has notified systemd of its new main process: %s", mainpid)
+
+}
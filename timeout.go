@@ -1,12 +1,13 @@
 package lemon
 
 import (
+	"context"
 	"errors"
 	"time"
 )
 
 const (
-	// DefaultTimeout is the default (and maximum) amount of time the engine will wait for a hook to gracefully shutdown.
+	// DefaultTimeout is the default amount of time the engine will wait for a hook to gracefully shutdown.
 	DefaultTimeout = 5 * time.Second
 )
 
@@ -16,12 +17,15 @@ var (
 )
 
 // Timeout define the maximum amount of time the engine will wait for hooks to gracefully shut down.
+// A hook may override this timeout with ShutdownTimeout or the Graceful interface.
 func (e *Engine) Timeout() time.Duration {
 	return e.timeout
 }
 
 // Timeout sets the maximum amount of time the engine will wait for hooks to gracefully shut down.
-// After this timeout, hooks will be forcefully shut down by destroying underlying goroutines.
+// After this timeout, the engine stops waiting for hooks: their goroutines are left running, and they're reported
+// in Report.Leaked once the engine has shutdown.
+// It's used by every hook that doesn't define its own timeout.
 func Timeout(timeout time.Duration) Option {
 	return wrapOption(func(e *Engine) error {
 
@@ -34,3 +38,128 @@ func Timeout(timeout time.Duration) Option {
 
 	})
 }
+
+// StartupTimeout sets the maximum amount of time the engine will wait for every hook to be ready, once it has
+// started. By default, the engine waits until every hook is ready, without any limit.
+//
+// If a hook isn't ready before this timeout, the engine shutdowns like after a failure: every hook that isn't ready
+// has failed with a HookError, wrapping ErrStartupTimeout, and the error returned by Start() joins all of them.
+func StartupTimeout(timeout time.Duration) Option {
+	return wrapOption(func(e *Engine) error {
+
+		if timeout <= 0 {
+			return ErrTimeout
+		}
+
+		e.startupTimeout = timeout
+		return nil
+
+	})
+}
+
+// ReadyTimeout sets the maximum amount of time the engine will wait for the registered hook to be ready, once it
+// has started. If the hook isn't ready before this timeout, the engine shutdowns like with StartupTimeout.
+// If given timeout is negative or equal zero, the engine will fail to start with ErrTimeout.
+func ReadyTimeout(timeout time.Duration) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+
+		if timeout <= 0 {
+			h.err = ErrTimeout
+			return
+		}
+
+		h.readyTimeout = timeout
+
+	})
+}
+
+// expect will shutdown the engine if given hook isn't ready before its own startup timeout, if defined.
+func (e *Engine) expect(ctx context.Context, h *hookEntry) {
+
+	if h.readyTimeout <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(h.readyTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		e.late(h)
+	case <-h.ready:
+	case <-ctx.Done():
+	}
+}
+
+// late will shutdown the engine if at least one of given hooks isn't ready, once its startup timeout has expired.
+// Every hook that isn't ready has failed, and the cause of the shutdown joins their error.
+// It returns false if every hook is ready.
+func (e *Engine) late(hooks ...*hookEntry) bool {
+
+	failures := []error{}
+	for _, h := range hooks {
+		if !h.isReady() {
+			err := wrapError(h.name, PhaseStart, ErrStartupTimeout)
+			e.failure(h, err)
+			e.expire(h)
+			e.publish(Event{Type: EventHookFailed, Hook: h.name, Err: err})
+			failures = append(failures, err)
+		}
+	}
+
+	switch len(failures) {
+	case 0:
+		return false
+	case 1:
+		e.abort(failures[0])
+	default:
+		e.abort(errors.Join(failures...))
+	}
+
+	return true
+}
+
+// expire marks given hook as failed once it has shutdown, since it wasn't ready before its startup timeout.
+func (e *Engine) expire(h *hookEntry) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	h.late = true
+}
+
+// Graceful is an optional interface for a Hook which requires its own amount of time to gracefully shutdown,
+// instead of the engine's timeout.
+type Graceful interface {
+	// ShutdownTimeout returns the maximum amount of time the engine will wait for this hook to shutdown.
+	// A zero or negative value fallbacks on the engine's timeout.
+	ShutdownTimeout() time.Duration
+}
+
+// ShutdownTimeout sets the maximum amount of time the engine will wait for the registered hook to gracefully
+// shut down. It overrides both the engine's timeout and the Graceful interface.
+// If given timeout is negative or equal zero, the engine will fail to start with ErrTimeout.
+func ShutdownTimeout(timeout time.Duration) HookOption {
+	return wrapHookOption(func(h *hookEntry) {
+
+		if timeout <= 0 {
+			h.err = ErrTimeout
+			return
+		}
+
+		h.timeout = timeout
+
+	})
+}
+
+// shutdownTimeout returns the maximum amount of time the engine will wait for given hook to gracefully shut down.
+// A hook in a stage never exceeds the budget of its stage.
+func (e *Engine) shutdownTimeout(h *hookEntry) time.Duration {
+	if h.timeout > 0 && (h.budget == 0 || h.timeout < h.budget) {
+		return h.timeout
+	}
+	if h.budget > 0 {
+		return h.budget
+	}
+	return e.timeout
+}
@@ -2,16 +2,25 @@ package lemon
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestTimeout(t *testing.T) {
 	tests := map[string]TestHandler{
-		"OkOption":  TimeoutOkOption,
-		"ErrOption": TimeoutErrOption,
-		"Start":     TimeoutStart,
-		"Stop":      TimeoutStop,
+		"OkOption":       TimeoutOkOption,
+		"ErrOption":      TimeoutErrOption,
+		"Start":          TimeoutStart,
+		"Stop":           TimeoutStop,
+		"Hook/Option":    TimeoutHookOption,
+		"Hook/Interface": TimeoutHookInterface,
+		"Hook/ErrOption": TimeoutHookErrOption,
+		"Startup/Engine": TimeoutStartupEngine,
+		"Startup/Hook":   TimeoutStartupHook,
+		"Startup/Ready":  TimeoutStartupReady,
+		"Startup/Option": TimeoutStartupErrOption,
 	}
 
 	for name, handler := range tests {
@@ -157,3 +166,260 @@ func TimeoutStop(runtime *TestRuntime) {
 	runtime.Log("Engine has shutdown with a correct timeout: %s.", delta)
 
 }
+
+type gracefulHook struct {
+	testHook
+	timeout time.Duration
+}
+
+func (g *gracefulHook) ShutdownTimeout() time.Duration {
+	return g.timeout
+}
+
+func TimeoutHookOption(runtime *TestRuntime) {
+
+	timeout := 3 * time.Second
+	kill := 200 * time.Millisecond
+	override := 300 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+	maximum := kill + override
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx, Timeout(timeout))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &testHook{kill: make(chan struct{}, 1)}
+	hook2 := &testHook{kill: make(chan struct{}), stopTimeout: true}
+
+	engine.Register(hook1)
+	engine.Register(hook2, ShutdownTimeout(override))
+
+	now := time.Now()
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InEpsilon(delta, maximum, epsilon, "Engine has shutdown with an unexpected amount of time...")
+	runtime.HasLifecycle(hook1, "hook1")
+
+	runtime.Log("Engine has shutdown with the hook's timeout: %s.", delta)
+
+}
+
+func TimeoutHookInterface(runtime *TestRuntime) {
+
+	timeout := 3 * time.Second
+	kill := 200 * time.Millisecond
+	override := 300 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+	maximum := kill + override
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), kill)
+	defer cancel()
+
+	engine, err := New(ctx, Timeout(timeout))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &gracefulHook{timeout: override}
+	hook.kill = make(chan struct{})
+	hook.stopTimeout = true
+
+	engine.Register(hook)
+
+	now := time.Now()
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	delta := time.Since(now)
+
+	runtime.InEpsilon(delta, maximum, epsilon, "Engine has shutdown with an unexpected amount of time...")
+
+	runtime.Log("Engine has shutdown with the hook's timeout: %s.", delta)
+
+}
+
+func TimeoutHookErrOption(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+	engine.Register(hook, ShutdownTimeout(-10*time.Millisecond))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrTimeout) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine can't start with a negative hook timeout.")
+
+}
+
+func TimeoutStartupEngine(runtime *TestRuntime) {
+
+	timeout := 200 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+
+	logger := &testLogger{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), StartupTimeout(timeout), Logger(logger.Handle))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook)
+	engine.Register(&readyHook{delay: -1}, Name("database"), ReportsReady())
+	engine.Register(&readyHook{delay: -1}, Name("cache"), ReportsReady())
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	now := time.Now()
+	report := engine.Run()
+	delta := time.Since(now)
+
+	err = report.Cause
+	runtime.IsHookError(err, "database", PhaseStart, ErrStartupTimeout)
+	runtime.InEpsilon(delta, timeout, epsilon, "Engine has shutdown with an unexpected amount of time...")
+	runtime.HasLifecycle(hook, "hook")
+
+	// The cause joins the error of every hook which isn't ready.
+	failure := &HookError{}
+	for _, name := range []string{"database", "cache"} {
+		if !strings.Contains(err.Error(), name) {
+			runtime.Error("Cause should name hook %s: %s", name, err)
+		}
+	}
+	if !errors.As(err, &failure) || !errors.Is(err, ErrStartupTimeout) {
+		runtime.Error("Unexpected cause: %s", err)
+	}
+
+	failures := logger.Failures()
+	if len(failures) != 2 {
+		runtime.Error("Two failures were expected: %+v", failures)
+	}
+
+	// The errors of each hook are joined by the cause, so they're only reported once.
+	if len(report.Errors()) != 1 {
+		runtime.Error("One error was expected: %+v", report.Errors())
+	}
+
+	runtime.HasHookStates(engine, StateStopped, StateFailed, StateFailed)
+
+	failed := []string{}
+	for _, event := range collect(events) {
+		if event.Type == EventHookFailed {
+			failed = append(failed, event.Hook)
+		}
+	}
+	if len(failed) != 2 || failed[0] != "database" || failed[1] != "cache" {
+		runtime.Error("Unexpected failed hooks: %+v", failed)
+	}
+
+	runtime.Log("Engine has shutdown after its startup timeout: %s", err)
+
+}
+
+func TimeoutStartupHook(runtime *TestRuntime) {
+
+	timeout := 200 * time.Millisecond
+	epsilon := 60 * time.Millisecond
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook)
+	engine.Register(&readyHook{delay: -1}, Name("database"), ReportsReady(), ReadyTimeout(timeout))
+
+	now := time.Now()
+	err = engine.Start()
+	delta := time.Since(now)
+
+	runtime.IsHookError(err, "database", PhaseStart, ErrStartupTimeout)
+	runtime.InEpsilon(delta, timeout, epsilon, "Engine has shutdown with an unexpected amount of time...")
+	runtime.HasLifecycle(hook, "hook")
+
+	runtime.Log("Engine has shutdown after a hook startup timeout: %s", err)
+
+}
+
+func TimeoutStartupReady(runtime *TestRuntime) {
+
+	ctx, cancel := context.WithTimeout(runtime.Context(), 300*time.Millisecond)
+	defer cancel()
+
+	engine, err := New(ctx, StartupTimeout(200*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	engine.Register(&readyHook{delay: 50 * time.Millisecond}, ReportsReady(), ReadyTimeout(100*time.Millisecond))
+
+	err = engine.Start()
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has ignored startup timeouts once every hook is ready.")
+
+}
+
+func TimeoutStartupErrOption(runtime *TestRuntime) {
+
+	_, err := New(runtime.Context(), StartupTimeout(0))
+	if err != ErrTimeout {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	engine, err := New(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Register(&testHook{kill: make(chan struct{}, 1)}, ReadyTimeout(-10*time.Millisecond))
+
+	err = engine.Start()
+	if !errors.Is(err, ErrTimeout) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine can't start with a negative startup timeout.")
+
+}
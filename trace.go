@@ -0,0 +1,99 @@
+package lemon
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// SpanStartup is the span of the engine's startup, until every hook is ready.
+	SpanStartup = "lemon.startup"
+	// SpanShutdown is the span of the engine's shutdown, from the moment it's required until every hook has
+	// shutdown.
+	SpanShutdown = "lemon.shutdown"
+	// SpanHookStart is the span of a hook's Start(), until the hook is ready.
+	SpanHookStart = "lemon.hook.start"
+	// SpanHookStop is the span of a hook's shutdown, until its Start() and Stop() have returned.
+	SpanHookStop = "lemon.hook.stop"
+)
+
+// Tracer opens spans around lifecycle operations of the engine: its startup and shutdown, and the startup and
+// shutdown of each hook. It's a minimal interface, which could be implemented by an adapter of a tracing library,
+// such as OpenTelemetry.
+type Tracer interface {
+	// StartSpan opens a span with given name, as a child of the span carried by given context, if any. The given
+	// hook is the name of the hook, or empty for a span of the engine. It returns a context that carries the new span.
+	StartSpan(ctx context.Context, name string, hook string) (context.Context, Span)
+}
+
+// Span is an operation traced by a Tracer.
+type Span interface {
+	// End ends the span, with the error of the operation, if any.
+	End(err error)
+}
+
+// Tracing defines the tracer used to trace the engine's lifecycle.
+//
+// The span of a hook's startup is carried by the context given to Start(), so a hook could create its own spans as
+// children. Spans of hooks are children of the engine's startup, or shutdown, span.
+func Tracing(tracer Tracer) Option {
+	return wrapOption(func(e *Engine) error {
+		e.tracer = tracer
+		return nil
+	})
+}
+
+// noopSpan is a span which is never recorded.
+type noopSpan struct{}
+
+func (noopSpan) End(err error) {}
+
+// trace opens a span with given name, and returns a function to end this span once.
+func (e *Engine) trace(ctx context.Context, name string, hook string) (context.Context, func(error)) {
+
+	var span Span = noopSpan{}
+	if e.tracer != nil {
+		ctx, span = e.tracer.StartSpan(ctx, name, hook)
+	}
+
+	once := &sync.Once{}
+	return ctx, func(err error) {
+		once.Do(func() {
+			span.End(err)
+		})
+	}
+}
+
+// traceStartup opens the span of the engine's startup.
+// It must be called with the engine's mutex.
+func (e *Engine) traceStartup() {
+	e.tracing, e.startup = e.trace(e.ctx, SpanStartup, "")
+}
+
+// traceShutdown ends the span of the engine's startup, if it's still pending, and opens the span of the engine's
+// shutdown.
+// It must be called with the engine's mutex.
+func (e *Engine) traceShutdown(cause error) {
+
+	if e.startup == nil || e.shutdown != nil {
+		return
+	}
+
+	if cause == nil {
+		cause = context.Canceled
+	}
+
+	e.startup(cause)
+	e.tracing, e.shutdown = e.trace(e.ctx, SpanShutdown, "")
+}
+
+// traceStop opens the span of given hook's shutdown, as a child of the current span of the engine.
+func (e *Engine) traceStop(h *hookEntry) func(error) {
+
+	e.mutex.Lock()
+	parent := e.tracing
+	e.mutex.Unlock()
+
+	_, end := e.trace(parent, SpanHookStop, h.name)
+	return end
+}
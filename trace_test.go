@@ -0,0 +1,184 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Lifecycle": TraceLifecycle,
+		"Failure":   TraceFailure,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// spanKey is the context key of a testSpan.
+type spanKey struct{}
+
+// testTracer records every span.
+type testTracer struct {
+	mutex sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	tracer *testTracer
+	name   string
+	hook   string
+	parent *testSpan
+	ended  bool
+	err    error
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string, hook string) (context.Context, Span) {
+
+	parent, _ := ctx.Value(spanKey{}).(*testSpan)
+	span := &testSpan{tracer: t, name: name, hook: hook, parent: parent}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *testSpan) End(err error) {
+
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	s.ended = true
+	s.err = err
+}
+
+// Find returns the span with given name and hook, which must have been opened and ended.
+func (t *testTracer) Find(runtime *TestRuntime, name string, hook string) testSpan {
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, span := range t.spans {
+		if span.name == name && span.hook == hook {
+			if !span.ended {
+				runtime.Error("Span %s of %q should have been ended", name, hook)
+			}
+			return *span
+		}
+	}
+
+	runtime.Error("Span %s of %q should have been opened", name, hook)
+	return testSpan{}
+}
+
+// spanHook records the span carried by the context given to Start().
+type spanHook struct {
+	testHook
+	span *testSpan
+}
+
+func (s *spanHook) Start(ctx context.Context) error {
+	s.testHook.mutex.Lock()
+	s.span, _ = ctx.Value(spanKey{}).(*testSpan)
+	s.testHook.mutex.Unlock()
+	return s.testHook.Start(ctx)
+}
+
+func TraceLifecycle(runtime *TestRuntime) {
+
+	tracer := &testTracer{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Tracing(tracer))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook1 := &spanHook{testHook: testHook{kill: make(chan struct{}, 1)}}
+	hook2 := &testHook{kill: make(chan struct{}, 1)}
+
+	engine.Register(hook1, Name("server"), DependsOn(hook2))
+	engine.Register(hook2, Name("database"))
+
+	result := runtime.StartEngine(engine)
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	startup := tracer.Find(runtime, SpanStartup, "")
+	shutdown := tracer.Find(runtime, SpanShutdown, "")
+
+	if startup.err != nil || shutdown.err != nil {
+		runtime.Error("Unexpected errors: %v, %v", startup.err, shutdown.err)
+	}
+
+	for _, name := range []string{"server", "database"} {
+
+		start := tracer.Find(runtime, SpanHookStart, name)
+		if start.parent == nil || start.parent.name != SpanStartup || start.err != nil {
+			runtime.Error("Unexpected span %s of %s: %+v", SpanHookStart, name, start)
+		}
+
+		stop := tracer.Find(runtime, SpanHookStop, name)
+		if stop.parent == nil || stop.parent.name != SpanShutdown || stop.err != nil {
+			runtime.Error("Unexpected span %s of %s: %+v", SpanHookStop, name, stop)
+		}
+	}
+
+	hook1.testHook.mutex.Lock()
+	defer hook1.testHook.mutex.Unlock()
+	if hook1.span == nil || hook1.span.name != SpanHookStart || hook1.span.hook != "server" {
+		runtime.Error("Hook's context should carry its span: %+v", hook1.span)
+	}
+
+	runtime.Log("Engine has traced its lifecycle.")
+
+}
+
+func TraceFailure(runtime *TestRuntime) {
+
+	tracer := &testTracer{}
+
+	engine, err := New(runtime.Context(), DisableSignal(), Tracing(tracer))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &testHook{startError: errors.New("an error has occurred: foobar")}
+	engine.Register(hook, Name("database"), ReportsReady())
+
+	err = engine.Start()
+	runtime.IsHookError(err, "database", PhaseStart, hook.startError)
+
+	for _, span := range []testSpan{
+		tracer.Find(runtime, SpanStartup, ""),
+		tracer.Find(runtime, SpanShutdown, ""),
+		tracer.Find(runtime, SpanHookStart, "database"),
+	} {
+		if !errors.Is(span.err, hook.startError) {
+			runtime.Error("Span %s should have ended with an error: %v", span.name, span.err)
+		}
+	}
+
+	runtime.Log("Engine has traced its failure.")
+
+}
@@ -0,0 +1,290 @@
+package lemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultUpgradeTimeout is the default amount of time the engine will wait for the new process to be ready.
+	DefaultUpgradeTimeout = 30 * time.Second
+)
+
+const (
+	// upgradeParentEnv contains the pid of the process that has started the new process with Upgrade().
+	upgradeParentEnv = "LEMON_UPGRADE_PID"
+	// upgradeReadyEnv contains the file descriptor used by the new process to report its readiness.
+	upgradeReadyEnv = "LEMON_UPGRADE_FD"
+	// upgradePIDFileEnv contains the file descriptor of the locked pid file, if any.
+	upgradePIDFileEnv = "LEMON_UPGRADE_PIDFILE"
+)
+
+var (
+	// ErrUpgradeTimeout is returned when the new process isn't ready before the upgrade timeout.
+	ErrUpgradeTimeout = errors.New("upgrade failed: new process is not ready before timeout")
+	// ErrUpgradeExited is returned when the new process has exited before being ready.
+	ErrUpgradeExited = errors.New("upgrade failed: new process has exited before being ready")
+)
+
+// reexec returns the command used to execute the current binary again, with the same arguments.
+var reexec = func() (*exec.Cmd, error) {
+
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd, nil
+}
+
+// UpgradeSignal sets the signal that triggers an upgrade, such as SIGUSR2. By default, an upgrade could only be
+// triggered with the engine's Upgrade(). A failed upgrade is published as an EventUpgradeFailed.
+func UpgradeSignal(signal os.Signal) Option {
+	return wrapOption(func(e *Engine) error {
+		e.upgradeSignal = signal
+		return nil
+	})
+}
+
+// UpgradeTimeout sets the maximum amount of time the engine will wait for the new process to be ready on upgrade.
+func UpgradeTimeout(timeout time.Duration) Option {
+	return wrapOption(func(e *Engine) error {
+
+		if timeout <= 0 {
+			return ErrTimeout
+		}
+
+		e.upgradeTimeout = timeout
+		return nil
+
+	})
+}
+
+// Upgrade will execute the current binary again, in a new process, to replace this one without downtime.
+//
+// Every socket obtained by hooks with Listen, Listener, ListenPacket or PacketConn is handed off to the new
+// process, which could claim them with the same functions and names. Once the engine of the new process is ready,
+// this engine shutdowns gracefully, like with Stop. With Systemd, the new process becomes the main process of the
+// service: its unit requires NotifyAccess=all, so systemd accepts the notifications of the new process.
+//
+// If the new process isn't ready before the upgrade timeout, or before given context is done, it's killed and an
+// error is returned: this engine keeps running. If the engine isn't running, ErrNotRunning is returned.
+func (e *Engine) Upgrade(ctx context.Context) error {
+
+	// Only one upgrade is executed at a time.
+	e.upgrading.Lock()
+	defer e.upgrading.Unlock()
+
+	if e.State() != StateRunning {
+		return ErrNotRunning
+	}
+
+	e.publish(Event{Type: EventUpgradeRequested})
+
+	err := e.upgrade(ctx)
+	if err != nil {
+		e.publish(Event{Type: EventUpgradeFailed, Err: err})
+		return err
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.upgraded = true
+
+	select {
+	case e.interrupt <- os.Interrupt:
+	default:
+	}
+
+	return nil
+}
+
+// upgrade will start a new process with the sockets of hooks, and wait until it's ready.
+func (e *Engine) upgrade(ctx context.Context) error {
+
+	ctx, cancel := context.WithTimeout(ctx, e.upgradeTimeout)
+	defer cancel()
+
+	cmd, err := reexec()
+	if err != nil {
+		return err
+	}
+
+	files, names := e.sockets.handoff()
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	cmd.Env = upgradeEnv(cmd.Env)
+
+	// Descriptors are given to the new process from 3, like the socket activation protocol.
+	cmd.ExtraFiles = append(append([]*os.File{}, files...), writer)
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("LISTEN_FDS=%d", len(files)),
+		fmt.Sprintf("LISTEN_FDNAMES=%s", strings.Join(names, ":")),
+		fmt.Sprintf("%s=%d", upgradeParentEnv, os.Getpid()),
+		fmt.Sprintf("%s=%d", upgradeReadyEnv, listenFdsStart+len(files)),
+	)
+
+	// The new process shares the lock of the pid file, so it's never released during the upgrade.
+	if e.pidFile != nil {
+		cmd.ExtraFiles = append(cmd.ExtraFiles, e.pidFile)
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", upgradePIDFileEnv, listenFdsStart+len(files)+1))
+	}
+
+	err = cmd.Start()
+	_ = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	// Release the new process once it has exited, whenever it occurs.
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	err = waitUpgrade(ctx, reader)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		<-exited
+		// The new process may have taken over the pid file right before being killed.
+		e.restorePIDFile()
+		return err
+	}
+
+	// systemd must supervise the new process before this one shuts down, otherwise the service is stopped.
+	e.notifyMainPID(cmd.Process.Pid)
+
+	return nil
+}
+
+// upgradeEnv returns given environment of the new process, or the one of this process if undefined, without the
+// variables of the socket activation and upgrade protocols.
+func upgradeEnv(env []string) []string {
+
+	if env == nil {
+		env = os.Environ()
+	}
+
+	values := []string{}
+	for _, value := range env {
+		if !strings.HasPrefix(value, "LISTEN_") && !strings.HasPrefix(value, "LEMON_UPGRADE_") {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// waitUpgrade will block until the new process reports its readiness on given pipe.
+// An error is returned if the new process has exited before, or if given context is done.
+func waitUpgrade(ctx context.Context, reader *os.File) error {
+
+	// The pipe is closed without any data if the new process has exited before being ready.
+	ready := make(chan bool, 1)
+	go func() {
+		buffer := make([]byte, 1)
+		n, _ := reader.Read(buffer)
+		ready <- n > 0
+	}()
+
+	select {
+	case ok := <-ready:
+		if ok {
+			return nil
+		}
+		return ErrUpgradeExited
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrUpgradeTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+// inheritUpgrade returns the pipe used to report the readiness of this process to its parent, and the locked pid
+// file if any, if it has been started by Upgrade(). Its variables are then removed from the environment, so they
+// aren't inherited by child processes.
+func inheritUpgrade() (*os.File, *os.File) {
+
+	defer func() {
+		_ = os.Unsetenv(upgradeParentEnv)
+		_ = os.Unsetenv(upgradeReadyEnv)
+		_ = os.Unsetenv(upgradePIDFileEnv)
+	}()
+
+	ppid, err := strconv.Atoi(os.Getenv(upgradeParentEnv))
+	if err != nil || ppid != os.Getppid() {
+		return nil, nil
+	}
+
+	fd, err := strconv.Atoi(os.Getenv(upgradeReadyEnv))
+	if err != nil || fd < listenFdsStart {
+		return nil, nil
+	}
+
+	ready := os.NewFile(uintptr(fd), "upgrade")
+
+	fd, err = strconv.Atoi(os.Getenv(upgradePIDFileEnv))
+	if err != nil || fd < listenFdsStart {
+		return ready, nil
+	}
+
+	return ready, os.NewFile(uintptr(fd), "pidfile")
+}
+
+// notifyUpgrade reports to the parent process that this engine is ready, if it has been started by Upgrade().
+// It takes over the pid file of its parent, if any, right before: the parent writes its pid again if the upgrade
+// fails anyway.
+// It must be called with the engine's mutex.
+func (e *Engine) notifyUpgrade() {
+	if e.handoff != nil {
+		_ = e.writePIDFile()
+		_, _ = e.handoff.Write([]byte("1"))
+		_ = e.handoff.Close()
+		e.handoff = nil
+	}
+}
+
+// waitUpgradeNotification will upgrade the process whenever the upgrade signal is received, until the engine
+// shuts down.
+func (e *Engine) waitUpgradeNotification() {
+
+	if e.upgradeSignal != nil {
+		signal.Notify(e.upgrades, e.upgradeSignal)
+		defer signal.Stop(e.upgrades)
+	}
+
+	for {
+		select {
+		case <-e.upgrades:
+			// A failure is already published as an event: it's not an error of a hook, so it's not logged.
+			_ = e.Upgrade(e.ctx)
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package lemon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestUpgrade(t *testing.T) {
+	tests := map[string]TestHandler{
+		"Handoff":    UpgradeWithHandoff,
+		"Timeout":    UpgradeWithTimeout,
+		"Exited":     UpgradeWithExitedProcess,
+		"NotRunning": UpgradeNotRunning,
+		"Signal":     UpgradeWithSignal,
+	}
+
+	for name, handler := range tests {
+		t.Run(name, Setup(handler))
+	}
+}
+
+// TestUpgradeChild is the new process started by Upgrade(), in given mode: it's skipped otherwise.
+func TestUpgradeChild(t *testing.T) {
+
+	mode := os.Getenv("LEMON_TEST_UPGRADE")
+	if mode == "" {
+		t.Skip("Only executed by Upgrade()")
+	}
+
+	if mode == "exit" {
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	options := []Option{DisableSignal()}
+	if os.Getenv("LEMON_TEST_PIDFILE") != "" {
+		options = append(options, PIDFile(os.Getenv("LEMON_TEST_PIDFILE")))
+	}
+
+	engine, err := New(ctx, options...)
+	if err != nil {
+		os.Exit(2)
+	}
+
+	switch mode {
+	case "stuck":
+		engine.Register(&readyHook{delay: -1}, ReportsReady())
+	case "fail":
+		engine.Register(&testHook{startError: errors.New("an error has occurred: foobar")}, ReportsReady())
+	default:
+		engine.Register(&serveHook{}, ReportsReady())
+	}
+
+	_ = engine.Start()
+}
+
+// serveHook answers a single connection on the inherited socket, then it returns.
+type serveHook struct{}
+
+func (serveHook) Start(ctx context.Context) error {
+
+	listener, err := Listener(ctx, "http")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	Ready(ctx)
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("child\n"))
+	return err
+}
+
+func (serveHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+// upgradeHook listens on a socket until it's stopped.
+type upgradeHook struct {
+	address chan net.Addr
+}
+
+func (u *upgradeHook) Start(ctx context.Context) error {
+
+	listener, err := Listen(ctx, "http", "tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	u.address <- listener.Addr()
+
+	<-ctx.Done()
+	return nil
+}
+
+func (u *upgradeHook) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Reexec replaces the new process started by Upgrade() with TestUpgradeChild in given mode.
+// If a pid file is given, the new process uses it.
+func (r *TestRuntime) Reexec(mode string, pidfile ...string) func() {
+
+	previous := reexec
+	reexec = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpgradeChild$")
+		cmd.Env = append(os.Environ(), "LEMON_TEST_UPGRADE="+mode)
+		if len(pidfile) > 0 {
+			cmd.Env = append(cmd.Env, "LEMON_TEST_PIDFILE="+pidfile[0])
+		}
+		cmd.Stderr = os.Stderr
+		return cmd, nil
+	}
+
+	return func() {
+		reexec = previous
+	}
+}
+
+func UpgradeWithHandoff(runtime *TestRuntime) {
+
+	cleanup := runtime.Reexec("serve")
+	defer cleanup()
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	address := <-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	event := runtime.FindEvent(collect(events), EventShutdownRequested)
+	if event.Trigger != TriggerUpgrade {
+		runtime.Error("Unexpected shutdown trigger: %s", event.Trigger)
+	}
+
+	// Only the new process is listening on the socket.
+	conn, err := net.DialTimeout("tcp", address.String(), time.Second)
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "child\n" {
+		runtime.Error("New process should answer on %s: %q (%v)", address, line, err)
+	}
+
+	runtime.Log("Engine has handed off its socket %s to the new process.", address)
+
+}
+
+func UpgradeWithTimeout(runtime *TestRuntime) {
+
+	cleanup := runtime.Reexec("stuck")
+	defer cleanup()
+
+	engine, err := New(runtime.Context(), DisableSignal(), UpgradeTimeout(500*time.Millisecond))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	<-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if !errors.Is(err, ErrUpgradeTimeout) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	if engine.State() != StateRunning {
+		runtime.Error("Engine should still be running: %s", engine.State())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has kept running after a failed upgrade.")
+
+}
+
+func UpgradeWithExitedProcess(runtime *TestRuntime) {
+
+	cleanup := runtime.Reexec("exit")
+	defer cleanup()
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	result := runtime.StartEngine(engine)
+	<-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if !errors.Is(err, ErrUpgradeExited) {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	if engine.State() != StateRunning {
+		runtime.Error("Engine should still be running: %s", engine.State())
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	runtime.Log("Engine has kept running after a failed upgrade.")
+
+}
+
+func UpgradeNotRunning(runtime *TestRuntime) {
+
+	engine, err := New(runtime.Context(), DisableSignal())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	err = engine.Upgrade(runtime.Context())
+	if err != ErrNotRunning {
+		runtime.Error("Unexpected error: %v", err)
+	}
+
+	runtime.Log("Engine cannot upgrade before it's running.")
+
+}
+
+func UpgradeWithSignal(runtime *TestRuntime) {
+
+	cleanup := runtime.Reexec("exit")
+	defer cleanup()
+
+	logger := &testLogger{}
+	handler := newTestHandler()
+
+	engine, err := New(runtime.Context(), DisableSignal(), UpgradeSignal(syscall.SIGUSR2),
+		Logger(logger.Handle), Slog(slog.New(handler)))
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+	if engine == nil {
+		runtime.Error("Engine must be defined")
+	}
+
+	hook := &upgradeHook{address: make(chan net.Addr, 1)}
+	engine.Register(hook)
+
+	events, unsubscribe := engine.Subscribe(64)
+	defer unsubscribe()
+
+	result := runtime.StartEngine(engine)
+	<-hook.address
+
+	err = engine.WaitReady(runtime.Context())
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	engine.upgrades <- syscall.SIGUSR2
+
+	for failed := false; !failed; {
+		select {
+		case event := <-events:
+			failed = event.Type == EventUpgradeFailed
+		case <-time.After(5 * time.Second):
+			runtime.Error("Upgrade should have failed")
+		}
+	}
+
+	engine.Stop()
+
+	err = <-result
+	if err != nil {
+		runtime.Error("An error wasn't expected: %s", err)
+	}
+
+	if len(logger.Failures()) != 0 {
+		runtime.Error("Upgrade failure shouldn't have been forwarded to the logger: %v", logger.Failures())
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	count := 0
+	for _, record := range *handler.records {
+		if record.message == "warning" {
+			runtime.Error("Upgrade failure shouldn't have been logged as a warning: %+v", record)
+		}
+		if record.message == "upgrade has failed" && record.level == slog.LevelError {
+			count++
+		}
+	}
+	if count != 1 {
+		runtime.Error("Upgrade failure should have been logged once: %+v", *handler.records)
+	}
+
+	runtime.Log("Engine has logged a failed upgrade once.")
+
+}